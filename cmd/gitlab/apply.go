@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	clientcmd "github.com/Cloud-for-You/devops-cli/cmd/gitlab/clientcmd"
+	declarative "github.com/Cloud-for-You/devops-cli/pkg/gitlab/declarative"
+)
+
+var (
+	configFilename string
+	planOutput     string
+)
+
+const declarativeHelp = `Groups, subgroups, projects, their direct members, groups they are shared
+with and key settings are declared in a YAML file. The file is compared with the
+live instance and only the differences are changed, so applying the same file
+again changes nothing.
+
+Attributes which are not set are not managed. A declared members or sharedWith
+list is authoritative: missing members and shares are removed, "members: []"
+removes all of them. Members root and the owner of the token are never changed.
+
+  groups:
+    - path: platform                 # full path of a top-level group
+      name: Platform
+      description: Platform team
+      visibility: private
+      members:
+        - username: alice
+          accessLevel: owner
+        - username: bob
+          accessLevel: developer
+          expiresAt: 2026-12-31
+      subgroups:
+        - path: backend              # platform/backend
+          sharedWith:
+            - group: security
+              accessLevel: reporter
+  projects:
+    - path: platform/backend/api     # full path of the project
+      description: Public API
+      visibility: internal
+      members: []
+      sharedWith:
+        - group: platform/qa
+          accessLevel: developer
+      settings:
+        defaultBranch: main
+        mergeMethod: ff              # merge, rebase_merge or ff
+        squashOption: default_on     # never, always, default_on or default_off
+        onlyAllowMergeIfPipelineSucceeds: true
+        onlyAllowMergeIfAllDiscussionsAreResolved: true
+        removeSourceBranchAfterMerge: true
+
+Access levels are guest, reporter, developer, maintainer and owner.`
+
+var PlanCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Show changes needed to reach the declared GitLab configuration",
+	Long: `The "plan" command prints the changes "apply" would make, nothing is changed.
+
+` + declarativeHelp,
+	Example: `  devops-cli gitlab-ce plan -f gitlab.yaml
+  devops-cli gitlab-ce plan -f gitlab.yaml -o json`,
+	Args:        cobra.NoArgs,
+	RunE:        planConfig,
+	Annotations: map[string]string{clientcmd.ScopesAnnotation: "read_api"},
+}
+
+var ApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Apply the declared GitLab configuration",
+	Long: `The "apply" command prints the plan and changes GitLab to match the declared
+configuration. A failed change does not stop the run, changes of a group or
+project which could not be created are skipped. A plan removing members or
+shares has to be confirmed, use --yes when not running in a terminal.
+
+` + declarativeHelp,
+	Example: `  devops-cli gitlab-ce apply -f gitlab.yaml --profile prod
+  devops-cli gitlab-ce apply -f gitlab.yaml --yes`,
+	Args:        cobra.NoArgs,
+	RunE:        applyConfig,
+	Annotations: map[string]string{clientcmd.ScopesAnnotation: "api"},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{PlanCmd, ApplyCmd} {
+		c.Flags().StringVarP(&configFilename, "filename", "f", "", "YAML file with the desired configuration, - reads the standard input (required)")
+		c.MarkFlagRequired("filename")
+	}
+	PlanCmd.Flags().StringVarP(&planOutput, "output", "o", "text", "output format: text or json")
+	clientcmd.AddConfirmFlag(ApplyCmd.Flags())
+}
+
+func planConfig(cmd *cobra.Command, args []string) error {
+	if planOutput != "text" && planOutput != "json" {
+		return fmt.Errorf("unsupported output format %q (supported: text, json)", planOutput)
+	}
+
+	config, err := declarative.Load(configFilename)
+	if err != nil {
+		return err
+	}
+	client, err := clientcmd.NewClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	plan, err := declarative.NewPlan(client, config)
+	if err != nil {
+		return err
+	}
+
+	if planOutput == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(plan)
+	}
+	printPlan(os.Stdout, plan)
+	return nil
+}
+
+func applyConfig(cmd *cobra.Command, args []string) error {
+	config, err := declarative.Load(configFilename)
+	if err != nil {
+		return err
+	}
+	client, err := clientcmd.NewClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	plan, err := declarative.NewPlan(client, config)
+	if err != nil {
+		return err
+	}
+	printPlan(os.Stdout, plan)
+	if plan.Empty() {
+		return nil
+	}
+
+	if err := clientcmd.Preflight(cmd, client, plan.Requirement(clientcmd.RequiredScopes(cmd))); err != nil {
+		return err
+	}
+
+	// Seznamy clenu a sdileni jsou autoritativni, odebrani musi uzivatel potvrdit
+	if _, _, remove := plan.Summary(); remove > 0 {
+		prompt := fmt.Sprintf("The plan removes %d members or shares.", remove)
+		if err := clientcmd.Confirm(cmd, prompt, "apply"); err != nil {
+			return err
+		}
+	}
+
+	result := declarative.Apply(client, plan, slog.Default())
+	fmt.Printf("\nApply finished: %d applied, %d failed, %d skipped.\n", len(result.Applied), len(result.Errors), len(result.Skipped))
+	if result.Failed() {
+		return fmt.Errorf("apply finished with errors")
+	}
+	return nil
+}
+
+func printPlan(w io.Writer, plan *declarative.Plan) {
+	if plan.Empty() {
+		fmt.Fprintln(w, "No changes, GitLab matches the configuration.")
+		return
+	}
+
+	for _, action := range plan.Actions {
+		fmt.Fprintln(w, action)
+		for _, detail := range action.Details {
+			fmt.Fprintf(w, "    %s\n", detail)
+		}
+	}
+	add, change, remove := plan.Summary()
+	fmt.Fprintf(w, "\nPlan: %d to add, %d to change, %d to remove.\n", add, change, remove)
+}
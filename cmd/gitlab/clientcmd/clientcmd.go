@@ -0,0 +1,125 @@
+// Package clientcmd creates GitLab clients from the persistent flags of the gitlab-ce command
+package clientcmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	gitlab "github.com/Cloud-for-You/devops-cli/pkg/gitlab"
+	secret "github.com/Cloud-for-You/devops-cli/pkg/secret"
+	client "gitlab.com/gitlab-org/api/client-go"
+)
+
+// AddFlags registers connection flags shared by all GitLab commands
+func AddFlags(flags *pflag.FlagSet) {
+	flags.String("profile", "", "GitLab instance profile from the configuration file, default currentProfile or $"+ProfileEnv)
+	flags.String("gitlabAuth", gitlab.AuthToken, "authentication method: token (personal/group/project access token), oauth2 (token or stored login), job-token (CI_JOB_TOKEN) or basic (username and password exchanged for OAuth2 token)")
+	flags.String("gitlabUsername", "", "username for --gitlabAuth basic")
+	flags.String("gitlabPassword", "", "password for --gitlabAuth basic, literal value or reference env:VAR, file:/path, vault:path#key")
+	secret.MarkFlag(flags, "gitlabPassword")
+	flags.String("gitlabOAuthClientID", "", "OAuth2 application ID used by login and to refresh stored tokens")
+	flags.String("gitlabCACert", "", "PEM bundle with CA certificates trusted in addition to the system ones")
+	flags.String("gitlabClientCert", "", "client certificate for mutual TLS")
+	flags.String("gitlabClientKey", "", "private key of the client certificate")
+	flags.Bool("gitlabInsecureSkipVerify", false, "do not verify GitLab TLS certificate (testing only)")
+	flags.String("gitlabProxy", "", "HTTP(S) proxy URL, default taken from HTTPS_PROXY/HTTP_PROXY/NO_PROXY")
+	flags.Duration("gitlabTimeout", 30*time.Second, "timeout of a single GitLab API request, 0 disables it")
+	flags.Int("gitlabRetries", 5, "maximum number of retries of failed or rate limited requests, -1 disables retrying")
+	flags.Duration("gitlabRetryWaitMin", 100*time.Millisecond, "minimal wait before a retry")
+	flags.Duration("gitlabRetryWaitMax", 400*time.Millisecond, "maximal wait before a retry, Retry-After header of rate limited requests takes precedence")
+	flags.Bool("skipPreflight", false, "do not verify token scopes and permissions before changing GitLab")
+	flags.String("gitlabUserAgent", gitlab.DefaultUserAgent, "User-Agent sent with every request, useful to tag automation in GitLab logs")
+
+	for _, name := range []string{
+		"gitlabAuth", "gitlabUsername", "gitlabPassword", "gitlabOAuthClientID", "gitlabCACert", "gitlabClientCert", "gitlabClientKey", "gitlabInsecureSkipVerify", "gitlabProxy",
+		"gitlabTimeout", "gitlabRetries", "gitlabRetryWaitMin", "gitlabRetryWaitMax", "gitlabUserAgent",
+	} {
+		viper.BindPFlag(name, flags.Lookup(name))
+	}
+}
+
+// Options returns client options from flags, the selected profile and the configuration file.
+// API requests are traced when debug logging is enabled.
+func Options(cmd *cobra.Command) (gitlab.ClientOptions, error) {
+	options, err := ConnectionOptions(cmd)
+	if err != nil {
+		return gitlab.ClientOptions{}, err
+	}
+
+	if options.Token == "" && options.AuthMethod == gitlab.AuthToken {
+		return gitlab.ClientOptions{}, fmt.Errorf("Gitlab token must be provided using the persistent flag --gitlabToken or a profile (--profile)")
+	}
+	return options, nil
+}
+
+// ConnectionOptions is Options without the check of credentials, used by login
+func ConnectionOptions(cmd *cobra.Command) (gitlab.ClientOptions, error) {
+	profile, err := ActiveProfile(cmd)
+	if err != nil {
+		return gitlab.ClientOptions{}, err
+	}
+	if profile == nil {
+		profile = &Profile{}
+	}
+
+	options := gitlab.ClientOptions{
+		URL:           setting(cmd, "gitlabUrl", profile.GitlabURL),
+		AuthMethod:    strings.ToLower(setting(cmd, "gitlabAuth", profile.GitlabAuth)),
+		Username:      setting(cmd, "gitlabUsername", profile.GitlabUsername),
+		OAuthClientID: setting(cmd, "gitlabOAuthClientID", profile.GitlabOAuthClientID),
+		CACert:        setting(cmd, "gitlabCACert", profile.CACert),
+		ClientCert:    setting(cmd, "gitlabClientCert", profile.ClientCert),
+		ClientKey:     setting(cmd, "gitlabClientKey", profile.ClientKey),
+		Proxy:         setting(cmd, "gitlabProxy", profile.Proxy),
+		Timeout:       viper.GetDuration("gitlabTimeout"),
+		Retries:       viper.GetInt("gitlabRetries"),
+		RetryWaitMin:  viper.GetDuration("gitlabRetryWaitMin"),
+		RetryWaitMax:  viper.GetDuration("gitlabRetryWaitMax"),
+		UserAgent:     viper.GetString("gitlabUserAgent"),
+		Trace:         slog.Default().Enabled(context.Background(), slog.LevelDebug),
+	}
+
+	options.InsecureSkipVerify = viper.GetBool("gitlabInsecureSkipVerify")
+	if flag := cmd.Flags().Lookup("gitlabInsecureSkipVerify"); flag != nil && !flag.Changed && profile.InsecureSkipVerify {
+		options.InsecureSkipVerify = true
+	}
+
+	if options.Token, err = secretSetting(cmd, "gitlabToken", profile.GitlabToken); err != nil {
+		return gitlab.ClientOptions{}, err
+	}
+	if options.Password, err = secretSetting(cmd, "gitlabPassword", profile.GitlabPassword); err != nil {
+		return gitlab.ClientOptions{}, err
+	}
+
+	// V GitLab CI je URL instance k dispozici v promenne CI_SERVER_URL
+	if options.URL == "" && options.AuthMethod == gitlab.AuthJobToken {
+		options.URL = os.Getenv("CI_SERVER_URL")
+	}
+	if options.URL == "" {
+		return gitlab.ClientOptions{}, fmt.Errorf("Gitlab URL must be provided using the persistent flag --gitlabUrl or a profile (--profile)")
+	}
+	if options.AuthMethod == gitlab.AuthOAuth2 {
+		if options.TokenFile, err = TokenFile(cmd, options.URL); err != nil {
+			return gitlab.ClientOptions{}, err
+		}
+	}
+
+	return options, nil
+}
+
+// NewClient creates GitLab client configured by the command flags
+func NewClient(cmd *cobra.Command) (*client.Client, error) {
+	options, err := Options(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return gitlab.NewClient(options)
+}
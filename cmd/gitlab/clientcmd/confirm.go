@@ -0,0 +1,41 @@
+package clientcmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// ErrAborted is returned when the user does not confirm a destructive action
+var ErrAborted = errors.New("aborted, nothing was changed")
+
+// AddConfirmFlag registers --yes of commands asking for confirmation
+func AddConfirmFlag(flags *pflag.FlagSet) {
+	flags.BoolP("yes", "y", false, "do not ask for confirmation")
+}
+
+// Confirm asks the user to type expected before a destructive action. Without
+// --yes the standard input must be a terminal, otherwise the command fails.
+func Confirm(cmd *cobra.Command, prompt, expected string) error {
+	if yes, _ := cmd.Flags().GetBool("yes"); yes {
+		return nil
+	}
+
+	// Bez terminalu (cron, CI, devops-cli run) se nelze zeptat
+	stat, err := os.Stdin.Stat()
+	if err != nil || stat.Mode()&os.ModeCharDevice == 0 {
+		return fmt.Errorf("confirmation required, use --yes when not running in a terminal")
+	}
+
+	fmt.Fprintf(os.Stderr, "%s\nType %q to confirm: ", prompt, expected)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil || strings.TrimSpace(answer) != expected {
+		return ErrAborted
+	}
+	return nil
+}
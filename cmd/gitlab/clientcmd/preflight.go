@@ -0,0 +1,38 @@
+package clientcmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+
+	gitlab "github.com/Cloud-for-You/devops-cli/pkg/gitlab"
+	client "gitlab.com/gitlab-org/api/client-go"
+)
+
+// Preflight verifies permissions before the command changes anything in GitLab.
+// Every failed check is logged and the command fails, --skipPreflight disables the checks.
+func Preflight(cmd *cobra.Command, client *client.Client, requirement gitlab.Requirement) error {
+	if skip, _ := cmd.Flags().GetBool("skipPreflight"); skip {
+		slog.Debug("pre-flight checks skipped")
+		return nil
+	}
+
+	report, err := gitlab.Preflight(client, requirement)
+	if err != nil {
+		return fmt.Errorf("pre-flight check failed: %w", err)
+	}
+
+	for _, check := range report.Checks {
+		slog.Debug("pre-flight check", "check", check.Check, "ok", check.OK, "detail", check.Detail)
+	}
+
+	failures := report.Failures()
+	for _, check := range failures {
+		slog.Error("pre-flight check failed", "check", check.Check, "detail", check.Detail)
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("user %s lacks permissions needed by the command (%d failed pre-flight checks), nothing was changed", report.User, len(failures))
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package clientcmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	secret "github.com/Cloud-for-You/devops-cli/pkg/secret"
+)
+
+// Profile is a named GitLab instance from the "profiles" section of the configuration file.
+// Keys are the same as the names of the gitlab-ce flags.
+type Profile struct {
+	GitlabURL string `mapstructure:"gitlabUrl" yaml:"gitlabUrl,omitempty"`
+	// GitlabAuth is token (default), oauth2, job-token or basic
+	GitlabAuth          string `mapstructure:"gitlabAuth" yaml:"gitlabAuth,omitempty"`
+	GitlabToken         string `mapstructure:"gitlabToken" yaml:"gitlabToken,omitempty"`
+	GitlabUsername      string `mapstructure:"gitlabUsername" yaml:"gitlabUsername,omitempty"`
+	GitlabPassword      string `mapstructure:"gitlabPassword" yaml:"gitlabPassword,omitempty"`
+	GitlabOAuthClientID string `mapstructure:"gitlabOAuthClientID" yaml:"gitlabOAuthClientID,omitempty"`
+
+	CACert             string `mapstructure:"gitlabCACert" yaml:"gitlabCACert,omitempty"`
+	ClientCert         string `mapstructure:"gitlabClientCert" yaml:"gitlabClientCert,omitempty"`
+	ClientKey          string `mapstructure:"gitlabClientKey" yaml:"gitlabClientKey,omitempty"`
+	InsecureSkipVerify bool   `mapstructure:"gitlabInsecureSkipVerify" yaml:"gitlabInsecureSkipVerify,omitempty"`
+	Proxy              string `mapstructure:"gitlabProxy" yaml:"gitlabProxy,omitempty"`
+	// Namespace is the default namespace (ID or full path) of new projects
+	Namespace string `mapstructure:"namespace" yaml:"namespace,omitempty"`
+}
+
+// ProfileEnv selects the profile when neither --profile nor currentProfile is set
+const ProfileEnv = "DEVOPS_CLI_PROFILE"
+
+// Profiles returns all profiles defined in the configuration file
+func Profiles() (map[string]Profile, error) {
+	profiles := map[string]Profile{}
+	if err := viper.UnmarshalKey("profiles", &profiles); err != nil {
+		return nil, fmt.Errorf("invalid profiles configuration: %w", err)
+	}
+	return profiles, nil
+}
+
+// ProfileName returns the selected profile: --profile flag, then the
+// DEVOPS_CLI_PROFILE variable, then currentProfile from the configuration file
+func ProfileName(cmd *cobra.Command) string {
+	if name, _ := cmd.Flags().GetString("profile"); name != "" {
+		return name
+	}
+	if name := os.Getenv(ProfileEnv); name != "" {
+		return name
+	}
+	return viper.GetString("currentProfile")
+}
+
+// ActiveProfile returns the selected profile, nil when no profile is selected
+func ActiveProfile(cmd *cobra.Command) (*Profile, error) {
+	name := ProfileName(cmd)
+	if name == "" {
+		return nil, nil
+	}
+
+	profiles, err := Profiles()
+	if err != nil {
+		return nil, err
+	}
+	profile, ok := profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("profile %q not found in the configuration file", name)
+	}
+	return &profile, nil
+}
+
+// TokenFile returns the file with OAuth2 tokens stored by login for the
+// selected profile, or for the GitLab host when no profile is selected
+func TokenFile(cmd *cobra.Command, gitlabURL string) (string, error) {
+	name := ProfileName(cmd)
+	if name == "" {
+		u, err := url.Parse(gitlabURL)
+		if err != nil || u.Host == "" {
+			return "", fmt.Errorf("invalid GitLab URL %q", gitlabURL)
+		}
+		name = strings.ReplaceAll(u.Host, ":", "_")
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine token location: %w", err)
+	}
+	return filepath.Join(home, ".config", "devops-cli", "tokens", name+".json"), nil
+}
+
+// setting returns the value of a string flag. The profile is used unless the
+// flag was given on the command line, the configuration file has the lowest priority.
+func setting(cmd *cobra.Command, name, profileValue string) string {
+	if flag := cmd.Flags().Lookup(name); (flag != nil && flag.Changed) || profileValue == "" {
+		return viper.GetString(name)
+	}
+	return profileValue
+}
+
+// secretSetting is setting for secret flags, references from the profile are resolved here
+// because the root command resolves only flag values
+func secretSetting(cmd *cobra.Command, name, profileValue string) (string, error) {
+	flag := cmd.Flags().Lookup(name)
+	if profileValue == "" || (flag != nil && flag.Changed) {
+		value, _ := cmd.Flags().GetString(name)
+		return value, nil
+	}
+
+	value, err := secret.Resolve(profileValue)
+	if err != nil {
+		return "", fmt.Errorf("profile %q: %s: %w", ProfileName(cmd), name, err)
+	}
+	return value, nil
+}
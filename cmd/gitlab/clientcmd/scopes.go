@@ -0,0 +1,20 @@
+package clientcmd
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// ScopesAnnotation lists token scopes a command needs separated by comma,
+// checked by whoami --check
+const ScopesAnnotation = "devops-cli/scopes"
+
+// RequiredScopes returns the token scopes the command needs, nil when unknown
+func RequiredScopes(cmd *cobra.Command) []string {
+	scopes, ok := cmd.Annotations[ScopesAnnotation]
+	if !ok || scopes == "" {
+		return nil
+	}
+	return strings.Split(scopes, ",")
+}
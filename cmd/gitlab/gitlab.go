@@ -4,18 +4,16 @@ Copyright © 2024 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
-	"fmt"
-	"log"
-
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	clientcmd "github.com/Cloud-for-You/devops-cli/cmd/gitlab/clientcmd"
 	group "github.com/Cloud-for-You/devops-cli/cmd/gitlab/group"
 	groupsync "github.com/Cloud-for-You/devops-cli/cmd/gitlab/groupsync"
 	list "github.com/Cloud-for-You/devops-cli/cmd/gitlab/list"
+	profile "github.com/Cloud-for-You/devops-cli/cmd/gitlab/profile"
 	project "github.com/Cloud-for-You/devops-cli/cmd/gitlab/project"
-	gitlab "github.com/Cloud-for-You/devops-cli/pkg/gitlab"
-	client "gitlab.com/gitlab-org/api/client-go"
+	secret "github.com/Cloud-for-You/devops-cli/pkg/secret"
 )
 
 var (
@@ -31,44 +29,25 @@ var GitlabCmd = &cobra.Command{
 	},
 }
 
-// Display the username currently user
-var WhoamiCmd = &cobra.Command{
-	Use:   "whoami",
-	Short: "Display the username currently user",
-	Run:   whoami,
-}
-
 func init() {
 	GitlabCmd.AddCommand(WhoamiCmd)
+	GitlabCmd.AddCommand(LoginCmd)
+	GitlabCmd.AddCommand(LogoutCmd)
+	GitlabCmd.AddCommand(PlanCmd)
+	GitlabCmd.AddCommand(ApplyCmd)
 	GitlabCmd.AddCommand(list.ListCmd)
 	GitlabCmd.AddCommand(project.RepositoryCmd)
 	GitlabCmd.AddCommand(group.GroupCmd)
 	GitlabCmd.AddCommand(groupsync.GroupSyncCmd)
+	GitlabCmd.AddCommand(profile.ProfileCmd)
 
 	// FLAGS
 	GitlabCmd.PersistentFlags().StringVar(&gitlabUrl, "gitlabUrl", "", "GitLab URL adresses")
 	viper.BindPFlag("gitlabUrl", GitlabCmd.PersistentFlags().Lookup("gitlabUrl"))
-	GitlabCmd.PersistentFlags().StringVar(&gitlabToken, "gitlabToken", "", "login token")
+	GitlabCmd.PersistentFlags().StringVar(&gitlabToken, "gitlabToken", "", "login token, literal value or reference env:VAR, file:/path, vault:path#key")
 	viper.BindPFlag("gitlabToken", GitlabCmd.PersistentFlags().Lookup("gitlabToken"))
+	secret.MarkFlag(GitlabCmd.PersistentFlags(), "gitlabToken")
 
-	GitlabCmd.MarkPersistentFlagRequired("gitlabUrl")
-	GitlabCmd.MarkPersistentFlagRequired("gitlabToken")
-}
-
-func whoami(cmd *cobra.Command, args []string) {
-	gitlabToken, _ := cmd.Flags().GetString("gitlabToken")
-	gitlabUrl, _ := cmd.Flags().GetString("gitlabUrl")
-
-	if gitlabToken == "" || gitlabUrl == "" {
-		log.Fatalf("Gitlab token and URL must be provided using the persistent flags --gitlabToken and --gitlabUrl")
-	}
-
-	client, err := client.NewClient(gitlabToken, client.WithBaseURL(gitlabUrl))
-	if err != nil {
-		log.Fatalf("Failed to create GitLab client: %v", err)
-	}
-
-	username, _ := gitlab.Whoami(client)
-	fmt.Println(*username)
-
+	// URL a token muzou pochazet i z profilu, povinnost overuje clientcmd
+	clientcmd.AddFlags(GitlabCmd.PersistentFlags())
 }
@@ -13,4 +13,12 @@ var GroupCmd = &cobra.Command{
 
 func init() {
 	GroupCmd.AddCommand(CreateCmd)
+	GroupCmd.AddCommand(GetCmd)
+	GroupCmd.AddCommand(UpdateCmd)
+	GroupCmd.AddCommand(DeleteCmd)
+	GroupCmd.AddCommand(RestoreCmd)
+	GroupCmd.AddCommand(TransferCmd)
+	GroupCmd.AddCommand(MembersCmd)
+	GroupCmd.AddCommand(ShareCmd)
+	GroupCmd.AddCommand(UnshareCmd)
 }
@@ -2,9 +2,10 @@ package cmd
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 
+	clientcmd "github.com/Cloud-for-You/devops-cli/cmd/gitlab/clientcmd"
 	gitlab "github.com/Cloud-for-You/devops-cli/pkg/gitlab"
 	"github.com/spf13/cobra"
 	client "gitlab.com/gitlab-org/api/client-go"
@@ -12,50 +13,143 @@ import (
 
 var (
 	groupName        string
+	groupPath        string
 	groupDescription string
 	visibility       string
+	parentGroup      string
+	createParents    bool
 )
 
 // Create GitLab group
 var CreateCmd = &cobra.Command{
-	Use:                   "create",
-	Short:                 "Create GitLab group",
+	Use:   "create",
+	Short: "Create GitLab group",
+	Long: `The "create" command creates a top-level group, or a subgroup of --parent.
+
+The path of the group is derived from the name when --path is not given: diacritics
+are removed, letters lowercased and other characters replaced by '-', so the name
+"Správa sítí" gets the path "sprava-siti". --parents creates missing groups of the
+--parent path like "mkdir -p".`,
+	Example: `  devops-cli gitlab-ce group create --name "Backend" --parent platform
+  devops-cli gitlab-ce group create --name "Správa sítí" --parent it/infra --parents`,
 	DisableFlagsInUseLine: true,
-	Run:                   createGroup,
+	RunE:                  createGroup,
+	Annotations:           map[string]string{clientcmd.ScopesAnnotation: "api"},
 }
 
 func init() {
 	CreateCmd.Flags().StringVar(&groupName, "name", "", "Name of the group (required)")
+	CreateCmd.Flags().StringVar(&groupPath, "path", "", "Path of the group, derived from the name when empty")
 	CreateCmd.Flags().StringVar(&groupDescription, "description", "", "Description of the group")
 	CreateCmd.Flags().StringVar(&visibility, "visibility", "private", "Visibility of the group (private, internal, public)")
+	CreateCmd.Flags().StringVar(&parentGroup, "parent", "", "Full path or ID of the parent group, creates a subgroup")
+	CreateCmd.Flags().BoolVar(&createParents, "parents", false, "Create missing parent groups")
 
 	CreateCmd.MarkFlagRequired("name")
 }
 
-func createGroup(cmd *cobra.Command, args []string) {
-	gitlabToken, _ := cmd.Flags().GetString("gitlabToken")
-	gitlabUrl, _ := cmd.Flags().GetString("gitlabUrl")
+func createGroup(cmd *cobra.Command, args []string) error {
+	options, err := createGroupOptions()
+	if err != nil {
+		return err
+	}
 
-	if gitlabToken == "" || gitlabUrl == "" {
-		log.Fatalf("Gitlab token and URL must be provided using the persistent flags --gitlabToken and --gitlabUrl")
+	client, err := clientcmd.NewClient(cmd)
+	if err != nil {
+		return err
 	}
 
-	client, err := client.NewClient(gitlabToken, client.WithBaseURL(gitlabUrl))
+	requirement, err := createRequirement(cmd, client)
 	if err != nil {
-		log.Fatalf("Failed to create GitLab client: %v", err)
+		return err
+	}
+	if err := clientcmd.Preflight(cmd, client, requirement); err != nil {
+		return err
+	}
+
+	fullPath := *options.Path
+	if parentGroup != "" {
+		parent, err := parentOf(client)
+		if err != nil {
+			return err
+		}
+		options.ParentID = &parent.ID
+		fullPath = parent.FullPath + "/" + fullPath
 	}
 
-	result, res, err := gitlab.CreateGroup(client, groupName, groupDescription, visibility)
+	result, res, err := gitlab.CreateGroupWithOptions(client, options)
 	if err != nil {
 		if res != nil && res.StatusCode == http.StatusConflict {
-			fmt.Printf("Group '%s' is exists.\n", groupName)
-		} else {
-			fmt.Printf("Failed to create GitLab group '%s': %v\n", groupName, err)
+			slog.Warn("group already exists", "group", fullPath)
+			return nil
+		}
+		return fmt.Errorf("failed to create GitLab group '%s': %w", fullPath, err)
+	}
+
+	fmt.Printf("Group created successfully\n")
+	fmt.Printf("Name: %s\n", result.Name)
+	fmt.Printf("Full path: %s\n", result.FullPath)
+	fmt.Printf("Description: %s\n", result.Description)
+	fmt.Printf("Web URL: %s\n", result.WebURL)
+	return nil
+}
+
+// parentOf returns the --parent group, missing groups are created with --parents
+func parentOf(glClient *client.Client) (*client.Group, error) {
+	if createParents {
+		return gitlab.EnsureGroupPath(glClient, parentGroup, visibility)
+	}
+	parent, err := gitlab.GetGroup(glClient, parentGroup)
+	if err != nil {
+		if gitlab.IsNotFound(err) {
+			return nil, fmt.Errorf("parent group '%s' does not exist, use --parents to create it", parentGroup)
 		}
+		return nil, fmt.Errorf("failed to get parent group '%s': %w", parentGroup, err)
+	}
+	return parent, nil
+}
+
+// createGroupOptions builds the group from flags, the path defaults to the slug of the name
+func createGroupOptions() (*client.CreateGroupOptions, error) {
+	if createParents && parentGroup == "" {
+		return nil, fmt.Errorf("--parents requires --parent")
+	}
+	path := groupPath
+	if path == "" {
+		var err error
+		if path, err = gitlab.Slugify(groupName); err != nil {
+			return nil, err
+		}
+	}
+	return &client.CreateGroupOptions{
+		Name:        client.Ptr(groupName),
+		Path:        client.Ptr(path),
+		Description: client.Ptr(groupDescription),
+		Visibility:  client.Ptr(client.VisibilityValue(visibility)),
+	}, nil
+}
+
+// createRequirement checks creation of a top-level group, or of a subgroup in the
+// deepest existing group of the parent path
+func createRequirement(cmd *cobra.Command, glClient *client.Client) (gitlab.Requirement, error) {
+	requirement := gitlab.Requirement{Scopes: clientcmd.RequiredScopes(cmd)}
+	if parentGroup == "" {
+		requirement.CreateGroups = true
+		return requirement, nil
+	}
+	if !createParents {
+		requirement.CreateSubgroupIn = []string{parentGroup}
+		return requirement, nil
+	}
+
+	existing, err := gitlab.ExistingGroupAncestor(glClient, parentGroup)
+	if err != nil {
+		return requirement, err
+	}
+	if existing == "" {
+		requirement.CreateGroups = true
 	} else {
-		fmt.Printf("Group created successfully\n")
-		fmt.Printf("Name: %s\n", result.Name)
-		fmt.Printf("Description: %s\n", result.Description)
-		fmt.Printf("Web URL: %s\n", result.WebURL)
+		requirement.CreateSubgroupIn = []string{existing}
 	}
+	return requirement, nil
 }
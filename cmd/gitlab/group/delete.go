@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	clientcmd "github.com/Cloud-for-You/devops-cli/cmd/gitlab/clientcmd"
+	gitlab "github.com/Cloud-for-You/devops-cli/pkg/gitlab"
+)
+
+var permanentlyRemove bool
+
+// Delete GitLab group
+var DeleteCmd = &cobra.Command{
+	Use:   "delete <group>",
+	Short: "Delete GitLab group given by full path or ID with all its subgroups and projects",
+	Long: `The "delete" command asks to type the full path of the group before deleting it,
+--yes skips the question.
+
+When delayed deletion is enabled on the instance, the group is only marked for
+deletion and can be restored with "group restore" until the shown date.
+--permanentlyRemove deletes a group which is already marked immediately.`,
+	Example: `  devops-cli gitlab-ce group delete platform/legacy
+  devops-cli gitlab-ce group delete platform/legacy --permanentlyRemove --yes`,
+	Args:        cobra.ExactArgs(1),
+	RunE:        deleteGroup,
+	Annotations: map[string]string{clientcmd.ScopesAnnotation: "api"},
+}
+
+// Restore GitLab group marked for deletion
+var RestoreCmd = &cobra.Command{
+	Use:         "restore <group>",
+	Short:       "Restore GitLab group marked for deletion",
+	Args:        cobra.ExactArgs(1),
+	RunE:        restoreGroup,
+	Annotations: map[string]string{clientcmd.ScopesAnnotation: "api"},
+}
+
+func init() {
+	DeleteCmd.Flags().BoolVar(&permanentlyRemove, "permanentlyRemove", false, "immediately delete a group already marked for deletion")
+	clientcmd.AddConfirmFlag(DeleteCmd.Flags())
+}
+
+func deleteGroup(cmd *cobra.Command, args []string) error {
+	client, err := clientcmd.NewClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	group, err := gitlab.GetGroup(client, args[0])
+	if err != nil {
+		return fmt.Errorf("failed to get GitLab group '%s': %w", args[0], err)
+	}
+	if permanentlyRemove && group.MarkedForDeletionOn == nil {
+		return fmt.Errorf("group '%s' is not marked for deletion, delete it first without --permanentlyRemove", group.FullPath)
+	}
+	if !permanentlyRemove && group.MarkedForDeletionOn != nil {
+		return fmt.Errorf("group '%s' is already marked for deletion on %s, use --permanentlyRemove to delete it now",
+			group.FullPath, time.Time(*group.MarkedForDeletionOn).Format(time.DateOnly))
+	}
+
+	if err := clientcmd.Preflight(cmd, client, gitlab.Requirement{
+		Scopes:  clientcmd.RequiredScopes(cmd),
+		OwnerOf: []string{group.FullPath},
+	}); err != nil {
+		return err
+	}
+
+	prompt := fmt.Sprintf("Group %s will be deleted including all subgroups and projects.", group.FullPath)
+	if err := clientcmd.Confirm(cmd, prompt, group.FullPath); err != nil {
+		return err
+	}
+
+	markedOn, err := gitlab.DeleteGroup(client, group.FullPath, permanentlyRemove)
+	if err != nil {
+		return err
+	}
+
+	if markedOn != nil {
+		slog.Info("group marked for deletion", "group", group.FullPath, "deletionOn", markedOn.Format(time.DateOnly))
+		fmt.Printf("Group %s is marked for deletion on %s, use \"group restore\" to keep it\n", group.FullPath, markedOn.Format(time.DateOnly))
+		return nil
+	}
+	slog.Info("group deleted", "group", group.FullPath)
+	fmt.Printf("Group %s deleted\n", group.FullPath)
+	return nil
+}
+
+func restoreGroup(cmd *cobra.Command, args []string) error {
+	client, err := clientcmd.NewClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	group, err := gitlab.RestoreGroup(client, args[0])
+	if err != nil {
+		return err
+	}
+
+	slog.Info("group restored", "group", group.FullPath)
+	fmt.Printf("Group %s restored\n", group.FullPath)
+	return nil
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	clientcmd "github.com/Cloud-for-You/devops-cli/cmd/gitlab/clientcmd"
+	gitlab "github.com/Cloud-for-You/devops-cli/pkg/gitlab"
+	client "gitlab.com/gitlab-org/api/client-go"
+)
+
+var getOutput string
+
+// Display GitLab group
+var GetCmd = &cobra.Command{
+	Use:         "get <group>",
+	Short:       "Display GitLab group given by full path or ID",
+	Example:     `  devops-cli gitlab-ce group get platform/backend -o json`,
+	Args:        cobra.ExactArgs(1),
+	RunE:        getGroup,
+	Annotations: map[string]string{clientcmd.ScopesAnnotation: "read_api"},
+}
+
+func init() {
+	GetCmd.Flags().StringVarP(&getOutput, "output", "o", "text", "output format: text or json")
+}
+
+func getGroup(cmd *cobra.Command, args []string) error {
+	if getOutput != "text" && getOutput != "json" {
+		return fmt.Errorf("unsupported output format %q (supported: text, json)", getOutput)
+	}
+
+	client, err := clientcmd.NewClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	group, err := gitlab.GetGroup(client, args[0])
+	if err != nil {
+		return fmt.Errorf("failed to get GitLab group '%s': %w", args[0], err)
+	}
+
+	if getOutput == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(group)
+	}
+	return printGroup(group)
+}
+
+func printGroup(group *client.Group) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "ID:\t%d\n", group.ID)
+	fmt.Fprintf(w, "Name:\t%s\n", group.Name)
+	fmt.Fprintf(w, "Full path:\t%s\n", group.FullPath)
+	fmt.Fprintf(w, "Description:\t%s\n", group.Description)
+	fmt.Fprintf(w, "Visibility:\t%s\n", group.Visibility)
+	if group.ParentID != 0 {
+		fmt.Fprintf(w, "Parent ID:\t%d\n", group.ParentID)
+	}
+	fmt.Fprintf(w, "Project creation:\t%s\n", group.ProjectCreationLevel)
+	fmt.Fprintf(w, "Subgroup creation:\t%s\n", group.SubGroupCreationLevel)
+	fmt.Fprintf(w, "Require 2FA:\t%t\n", group.RequireTwoFactorAuth)
+	if group.RequireTwoFactorAuth {
+		fmt.Fprintf(w, "2FA grace period:\t%dh\n", group.TwoFactorGracePeriod)
+	}
+	if protection := gitlab.BranchProtectionName(group.DefaultBranchProtectionDefaults); protection != "" {
+		fmt.Fprintf(w, "Default branch protection:\t%s\n", protection)
+	}
+	if group.MarkedForDeletionOn != nil {
+		fmt.Fprintf(w, "Marked for deletion on:\t%s\n", time.Time(*group.MarkedForDeletionOn).Format(time.DateOnly))
+	}
+	fmt.Fprintf(w, "Web URL:\t%s\n", group.WebURL)
+	return w.Flush()
+}
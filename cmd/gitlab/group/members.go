@@ -0,0 +1,436 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	clientcmd "github.com/Cloud-for-You/devops-cli/cmd/gitlab/clientcmd"
+	common "github.com/Cloud-for-You/devops-cli/pkg"
+	gitlab "github.com/Cloud-for-You/devops-cli/pkg/gitlab"
+	client "gitlab.com/gitlab-org/api/client-go"
+)
+
+var (
+	membersOutput     string
+	membersInherited  bool
+	membersFilename   string
+	memberAccessLevel string
+	memberExpiresAt   string
+)
+
+const membersFileHelp = `
+Users are given as arguments or with --filename as CSV lines
+"username,accessLevel,expiresAt" ("-" reads standard input). Access level and
+expiration of a line override --accessLevel and --expiresAt, lines starting with
+'#' and the header line are skipped, so the output of "members list -o csv" can
+be used as input. Lines with "inherited" in the fifth (membership) column are
+skipped, inherited members do not become direct ones.`
+
+// Manage members of GitLab group
+var MembersCmd = &cobra.Command{
+	Use:                   "members",
+	Short:                 "Manage members of GitLab group",
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+// List members of GitLab group
+var MembersListCmd = &cobra.Command{
+	Use:   "list <group>",
+	Short: "List members of GitLab group given by full path or ID",
+	Long: `The "list" command shows direct members of the group, --inherited adds members
+inherited from parent groups and the MEMBERSHIP column tells them apart.`,
+	Example: `  devops-cli gitlab-ce group members list platform/backend --inherited
+  devops-cli gitlab-ce group members list platform/backend -o csv > members.csv`,
+	Args:        cobra.ExactArgs(1),
+	RunE:        listMembers,
+	Annotations: map[string]string{clientcmd.ScopesAnnotation: "read_api"},
+}
+
+// Add members to GitLab group
+var MembersAddCmd = &cobra.Command{
+	Use:   "add <group> [username...]",
+	Short: "Add users to GitLab group",
+	Long:  `The "add" command adds users as direct members of the group.` + "\n" + membersFileHelp,
+	Example: `  devops-cli gitlab-ce group members add platform/backend alice bob --accessLevel developer --expiresAt 2026-12-31
+  cat access-requests.csv | devops-cli gitlab-ce group members add platform/backend -f -`,
+	Args:        cobra.MinimumNArgs(1),
+	RunE:        addMembers,
+	Annotations: map[string]string{clientcmd.ScopesAnnotation: "api"},
+}
+
+// Update members of GitLab group
+var MembersUpdateCmd = &cobra.Command{
+	Use:   "update <group> [username...]",
+	Short: "Change access level or expiration of GitLab group members",
+	Long: `The "update" command changes direct members of the group, settings which are
+not given are kept. --expiresAt never removes the expiration.` + "\n" + membersFileHelp,
+	Example: `  devops-cli gitlab-ce group members update platform/backend alice --accessLevel maintainer
+  devops-cli gitlab-ce group members update platform/backend bob --expiresAt never`,
+	Args:        cobra.MinimumNArgs(1),
+	RunE:        updateMembers,
+	Annotations: map[string]string{clientcmd.ScopesAnnotation: "api"},
+}
+
+// Remove members from GitLab group
+var MembersRemoveCmd = &cobra.Command{
+	Use:   "remove <group> [username...]",
+	Short: "Remove users from GitLab group",
+	Long: `The "remove" command removes direct members of the group, inherited members
+have to be removed from the parent group.` + "\n" + membersFileHelp,
+	Example:     `  devops-cli gitlab-ce group members remove platform/backend alice`,
+	Args:        cobra.MinimumNArgs(1),
+	RunE:        removeMembers,
+	Annotations: map[string]string{clientcmd.ScopesAnnotation: "api"},
+}
+
+func init() {
+	MembersListCmd.Flags().StringVarP(&membersOutput, "output", "o", "text", "output format: text, json or csv")
+	MembersListCmd.Flags().BoolVar(&membersInherited, "inherited", false, "include members inherited from parent groups")
+
+	MembersAddCmd.Flags().StringVar(&memberAccessLevel, "accessLevel", "", "access level: guest, reporter, developer, maintainer or owner")
+	MembersAddCmd.Flags().StringVar(&memberExpiresAt, "expiresAt", "", "expiration date of the membership (YYYY-MM-DD)")
+	MembersUpdateCmd.Flags().StringVar(&memberAccessLevel, "accessLevel", "", "new access level: guest, reporter, developer, maintainer or owner")
+	MembersUpdateCmd.Flags().StringVar(&memberExpiresAt, "expiresAt", "", "new expiration date (YYYY-MM-DD), never removes the expiration")
+	for _, cmd := range []*cobra.Command{MembersAddCmd, MembersUpdateCmd, MembersRemoveCmd} {
+		cmd.Flags().StringVarP(&membersFilename, "filename", "f", "", "CSV file with users, - reads standard input")
+	}
+
+	MembersCmd.AddCommand(MembersListCmd)
+	MembersCmd.AddCommand(MembersAddCmd)
+	MembersCmd.AddCommand(MembersUpdateCmd)
+	MembersCmd.AddCommand(MembersRemoveCmd)
+}
+
+// memberRow is one line of "members list" output
+type memberRow struct {
+	Username    string `json:"username"`
+	Name        string `json:"name"`
+	AccessLevel string `json:"accessLevel"`
+	ExpiresAt   string `json:"expiresAt,omitempty"`
+	Membership  string `json:"membership"`
+}
+
+func listMembers(cmd *cobra.Command, args []string) error {
+	if membersOutput != "text" && membersOutput != "json" && membersOutput != "csv" {
+		return fmt.Errorf("unsupported output format %q (supported: text, json, csv)", membersOutput)
+	}
+
+	client, err := clientcmd.NewClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	direct, err := gitlab.ListGitlabGroupMembers(client, args[0])
+	if err != nil {
+		return err
+	}
+	members := direct
+	if membersInherited {
+		if members, err = gitlab.ListAllGroupMembers(client, args[0]); err != nil {
+			return err
+		}
+	}
+
+	directIDs := make(map[int]bool, len(direct))
+	for _, m := range direct {
+		directIDs[m.ID] = true
+	}
+	rows := []memberRow{}
+	for _, m := range members {
+		row := memberRow{
+			Username:    m.Username,
+			Name:        m.Name,
+			AccessLevel: gitlab.AccessLevelName(m.AccessLevel),
+			Membership:  "inherited",
+		}
+		if m.ExpiresAt != nil {
+			row.ExpiresAt = time.Time(*m.ExpiresAt).Format(time.DateOnly)
+		}
+		if directIDs[m.ID] {
+			row.Membership = "direct"
+		}
+		rows = append(rows, row)
+	}
+
+	switch membersOutput {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(rows)
+	case "csv":
+		return writeMembersCSV(os.Stdout, rows)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "USERNAME\tNAME\tACCESS\tEXPIRES\tMEMBERSHIP")
+	for _, row := range rows {
+		expires := row.ExpiresAt
+		if expires == "" {
+			expires = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", row.Username, row.Name, row.AccessLevel, expires, row.Membership)
+	}
+	return w.Flush()
+}
+
+// writeMembersCSV writes the rows as CSV, the first three columns match the input of --filename
+func writeMembersCSV(output io.Writer, rows []memberRow) error {
+	w := csv.NewWriter(output)
+	w.Write([]string{"username", "accessLevel", "expiresAt", "name", "membership"})
+	for _, row := range rows {
+		w.Write([]string{row.Username, row.AccessLevel, row.ExpiresAt, row.Name, row.Membership})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// memberEntry is a user given on the command line or a line of --filename,
+// empty fields are taken from the flags
+type memberEntry struct {
+	username    string
+	accessLevel string
+	expiresAt   string
+}
+
+func addMembers(cmd *cobra.Command, args []string) error {
+	entries, err := memberEntries(args[1:])
+	if err != nil {
+		return err
+	}
+
+	type addition struct {
+		username  string
+		level     client.AccessLevelValue
+		expiresAt *time.Time
+	}
+	var additions []addition
+	for _, entry := range entries {
+		if entry.accessLevel == "" {
+			return fmt.Errorf("access level of user '%s' is missing, set --accessLevel", entry.username)
+		}
+		level, err := gitlab.ParseAccessLevel(entry.accessLevel)
+		if err != nil {
+			return fmt.Errorf("user '%s': %w", entry.username, err)
+		}
+		expiresAt, err := common.ParseDate(entry.expiresAt)
+		if err != nil {
+			return fmt.Errorf("user '%s': %w", entry.username, err)
+		}
+		additions = append(additions, addition{entry.username, level, expiresAt})
+	}
+
+	client, err := membersClient(cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	return forEachMember(args[0], "added", len(additions), func(i int) (string, error) {
+		a := additions[i]
+		return a.username, gitlab.AddGroupMember(client, args[0], a.username, a.level, a.expiresAt)
+	})
+}
+
+func updateMembers(cmd *cobra.Command, args []string) error {
+	entries, err := memberEntries(args[1:])
+	if err != nil {
+		return err
+	}
+
+	type update struct {
+		username   string
+		level      *client.AccessLevelValue
+		expiresAt  *time.Time
+		keepExpiry bool
+	}
+	var updates []update
+	for _, entry := range entries {
+		if entry.accessLevel == "" && entry.expiresAt == "" {
+			return fmt.Errorf("nothing to update for user '%s', set --accessLevel or --expiresAt", entry.username)
+		}
+		u := update{username: entry.username}
+		if entry.accessLevel != "" {
+			level, err := gitlab.ParseAccessLevel(entry.accessLevel)
+			if err != nil {
+				return fmt.Errorf("user '%s': %w", entry.username, err)
+			}
+			u.level = &level
+		}
+		expiresAt, err := common.ParseDate(entry.expiresAt)
+		if err != nil {
+			return fmt.Errorf("user '%s': %w", entry.username, err)
+		}
+		// Bez data expirace ponechame, "never" ji odstrani
+		u.expiresAt, u.keepExpiry = expiresAt, entry.expiresAt == ""
+		updates = append(updates, u)
+	}
+
+	client, err := membersClient(cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	// API vyzaduje access level a nezmenenou expiraci odstrani, posilame proto
+	// vzdy obe hodnoty, nezmenene prevezmeme z aktualniho clenstvi
+	direct, err := gitlab.ListGitlabGroupMembers(client, args[0])
+	if err != nil {
+		return err
+	}
+	current := make(map[string]common.Member, len(direct))
+	for _, m := range direct {
+		current[strings.ToLower(m.Username)] = common.Member{Name: m.Username, AccessLevel: m.AccessLevel, ExpiresAt: (*time.Time)(m.ExpiresAt)}
+	}
+
+	return forEachMember(args[0], "updated", len(updates), func(i int) (string, error) {
+		u := updates[i]
+		member, ok := current[strings.ToLower(u.username)]
+		if !ok {
+			return u.username, fmt.Errorf("user is not a direct member of the group")
+		}
+		level, expiresAt := member.AccessLevel, member.ExpiresAt
+		if u.level != nil {
+			level = *u.level
+		}
+		if !u.keepExpiry {
+			expiresAt = u.expiresAt
+		}
+		return u.username, gitlab.EditGroupMember(client, args[0], u.username, level, expiresAt)
+	})
+}
+
+func removeMembers(cmd *cobra.Command, args []string) error {
+	entries, err := memberEntries(args[1:])
+	if err != nil {
+		return err
+	}
+
+	client, err := membersClient(cmd, args[0])
+	if err != nil {
+		return err
+	}
+
+	return forEachMember(args[0], "removed", len(entries), func(i int) (string, error) {
+		return entries[i].username, gitlab.RemoveGroupMember(client, args[0], entries[i].username)
+	})
+}
+
+// membersClient returns the client after checking the user may change members of the group
+func membersClient(cmd *cobra.Command, group string) (*client.Client, error) {
+	glClient, err := clientcmd.NewClient(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if err := clientcmd.Preflight(cmd, glClient, gitlab.Requirement{
+		Scopes:  clientcmd.RequiredScopes(cmd),
+		OwnerOf: []string{group},
+	}); err != nil {
+		return nil, err
+	}
+	return glClient, nil
+}
+
+// forEachMember runs change for all members, a failed member does not stop the others
+func forEachMember(group, verb string, count int, change func(i int) (string, error)) error {
+	failed := 0
+	for i := 0; i < count; i++ {
+		username, err := change(i)
+		if err != nil {
+			slog.Error("group member was not "+verb, "group", group, "user", username, "error", err)
+			failed++
+			continue
+		}
+		slog.Info("group member "+verb, "group", group, "user", username)
+	}
+
+	fmt.Printf("%d members %s, %d failed\n", count-failed, verb, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d members were not %s", failed, count, verb)
+	}
+	return nil
+}
+
+// memberEntries returns users given as arguments followed by users from --filename
+func memberEntries(usernames []string) ([]memberEntry, error) {
+	var entries []memberEntry
+	for _, username := range usernames {
+		entries = append(entries, memberEntry{username: username})
+	}
+
+	if membersFilename != "" {
+		fromFile, err := readMemberEntries(membersFilename)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, fromFile...)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no users given, pass usernames as arguments or use --filename")
+	}
+
+	for i := range entries {
+		if entries[i].accessLevel == "" {
+			entries[i].accessLevel = memberAccessLevel
+		}
+		if entries[i].expiresAt == "" {
+			entries[i].expiresAt = memberExpiresAt
+		}
+	}
+	return entries, nil
+}
+
+func readMemberEntries(filename string) ([]memberEntry, error) {
+	var input io.Reader = os.Stdin
+	if filename != "-" {
+		file, err := os.Open(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open member file: %w", err)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	reader := csv.NewReader(input)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var entries []memberEntry
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read member file: %w", err)
+		}
+
+		field := func(i int) string {
+			if i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		if field(0) == "" || strings.EqualFold(field(0), "username") {
+			continue
+		}
+		// Zdedene cleny z "members list --inherited" nepridavame jako prime
+		if strings.EqualFold(field(4), "inherited") {
+			slog.Info("inherited member skipped", "user", field(0))
+			continue
+		}
+		entries = append(entries, memberEntry{username: field(0), accessLevel: field(1), expiresAt: field(2)})
+	}
+	return entries, nil
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeMemberFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "members.csv")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadMemberEntries(t *testing.T) {
+	path := writeMemberFile(t, `username,accessLevel,expiresAt
+# access requests
+alice,developer,2026-12-31
+ bob , maintainer
+carol
+
+,reporter
+dave,,never
+`)
+
+	entries, err := readMemberEntries(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []memberEntry{
+		{username: "alice", accessLevel: "developer", expiresAt: "2026-12-31"},
+		{username: "bob", accessLevel: "maintainer"},
+		{username: "carol"},
+		{username: "dave", expiresAt: "never"},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("got %+v, want %+v", entries, want)
+	}
+
+	if _, err := readMemberEntries(path + ".missing"); err == nil || !strings.Contains(err.Error(), "failed to open member file") {
+		t.Errorf("missing file: error %v", err)
+	}
+}
+
+func TestMemberEntries(t *testing.T) {
+	membersFilename = writeMemberFile(t, "alice,developer,2026-12-31\nbob\n")
+	memberAccessLevel, memberExpiresAt = "reporter", "2026-06-30"
+	defer func() { membersFilename, memberAccessLevel, memberExpiresAt = "", "", "" }()
+
+	entries, err := memberEntries([]string{"carol"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Prazdna pole doplni --accessLevel a --expiresAt
+	want := []memberEntry{
+		{username: "carol", accessLevel: "reporter", expiresAt: "2026-06-30"},
+		{username: "alice", accessLevel: "developer", expiresAt: "2026-12-31"},
+		{username: "bob", accessLevel: "reporter", expiresAt: "2026-06-30"},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("got %+v, want %+v", entries, want)
+	}
+
+	membersFilename = ""
+	if _, err := memberEntries(nil); err == nil || !strings.Contains(err.Error(), "no users given") {
+		t.Errorf("no users: error %v", err)
+	}
+}
+
+func TestMembersCSVRoundTrip(t *testing.T) {
+	rows := []memberRow{
+		{Username: "alice", Name: "Alice Smith, Jr.", AccessLevel: "developer", ExpiresAt: "2026-12-31", Membership: "direct"},
+		{Username: "bob", Name: "Bob", AccessLevel: "maintainer", Membership: "direct"},
+		{Username: "carol", Name: "Carol", AccessLevel: "owner", Membership: "inherited"},
+	}
+
+	var out bytes.Buffer
+	if err := writeMembersCSV(&out, rows); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := readMemberEntries(writeMemberFile(t, out.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Zdedeny clen z "list --inherited" se nestane primym
+	want := []memberEntry{
+		{username: "alice", accessLevel: "developer", expiresAt: "2026-12-31"},
+		{username: "bob", accessLevel: "maintainer"},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("got %+v, want %+v\n%s", entries, want, out.String())
+	}
+}
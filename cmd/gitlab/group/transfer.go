@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+
+	clientcmd "github.com/Cloud-for-You/devops-cli/cmd/gitlab/clientcmd"
+	gitlab "github.com/Cloud-for-You/devops-cli/pkg/gitlab"
+)
+
+var (
+	transferParent   string
+	transferTopLevel bool
+)
+
+// Transfer GitLab group under a new parent
+var TransferCmd = &cobra.Command{
+	Use:   "transfer <group>",
+	Short: "Move GitLab group under a new parent group",
+	Long: `The "transfer" command moves the group given by full path or ID with all its
+subgroups and projects under the --parent group, --topLevel turns a subgroup into
+a top-level group. The full path of the group and of all its projects changes.`,
+	Example: `  devops-cli gitlab-ce group transfer backend --parent platform
+  devops-cli gitlab-ce group transfer platform/legacy --topLevel`,
+	Args:        cobra.ExactArgs(1),
+	RunE:        transferGroup,
+	Annotations: map[string]string{clientcmd.ScopesAnnotation: "api"},
+}
+
+func init() {
+	TransferCmd.Flags().StringVar(&transferParent, "parent", "", "full path or ID of the new parent group")
+	TransferCmd.Flags().BoolVar(&transferTopLevel, "topLevel", false, "turn the subgroup into a top-level group")
+	TransferCmd.MarkFlagsMutuallyExclusive("parent", "topLevel")
+	TransferCmd.MarkFlagsOneRequired("parent", "topLevel")
+}
+
+func transferGroup(cmd *cobra.Command, args []string) error {
+	client, err := clientcmd.NewClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	requirement := gitlab.Requirement{
+		Scopes:       clientcmd.RequiredScopes(cmd),
+		OwnerOf:      []string{args[0]},
+		CreateGroups: transferTopLevel,
+	}
+	if transferParent != "" {
+		requirement.OwnerOf = append(requirement.OwnerOf, transferParent)
+	}
+	if err := clientcmd.Preflight(cmd, client, requirement); err != nil {
+		return err
+	}
+
+	group, err := gitlab.TransferGroup(client, args[0], transferParent)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("group transferred", "group", args[0], "fullPath", group.FullPath)
+	fmt.Printf("Group transferred to %s\n", group.FullPath)
+	fmt.Printf("Web URL: %s\n", group.WebURL)
+	return nil
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	clientcmd "github.com/Cloud-for-You/devops-cli/cmd/gitlab/clientcmd"
+	gitlab "github.com/Cloud-for-You/devops-cli/pkg/gitlab"
+	client "gitlab.com/gitlab-org/api/client-go"
+)
+
+// Update GitLab group
+var UpdateCmd = &cobra.Command{
+	Use:   "update <group>",
+	Short: "Update settings of GitLab group given by full path or ID",
+	Long: `The "update" command changes only the settings given on the command line.
+
+Default branch protection of new projects (--defaultBranchProtection):
+  none            developers can push and force push
+  partial         developers can push, force push is not allowed
+  push-protected  developers can merge, only maintainers push
+  full            only maintainers push and merge
+  initial-push    full protection, developers can push the initial commit`,
+	Example: `  devops-cli gitlab-ce group update platform/backend \
+	--visibility internal \
+	--projectCreationLevel maintainer \
+	--requireTwoFactorAuth --twoFactorGracePeriod 48`,
+	Args:        cobra.ExactArgs(1),
+	RunE:        updateGroup,
+	Annotations: map[string]string{clientcmd.ScopesAnnotation: "api"},
+}
+
+func init() {
+	UpdateCmd.Flags().String("name", "", "new name of the group")
+	UpdateCmd.Flags().String("description", "", "new description of the group")
+	UpdateCmd.Flags().String("visibility", "", "visibility of the group (private, internal, public)")
+	UpdateCmd.Flags().String("projectCreationLevel", "", "who can create projects: noone, owner, maintainer or developer")
+	UpdateCmd.Flags().String("subgroupCreationLevel", "", "who can create subgroups: owner or maintainer")
+	UpdateCmd.Flags().Bool("requireTwoFactorAuth", false, "require two-factor authentication of all members")
+	UpdateCmd.Flags().Int("twoFactorGracePeriod", 48, "hours members have to set up two-factor authentication")
+	UpdateCmd.Flags().String("defaultBranchProtection", "", "default branch protection of new projects: none, partial, push-protected, full or initial-push")
+}
+
+func updateGroup(cmd *cobra.Command, args []string) error {
+	options, err := updateGroupOptions(cmd)
+	if err != nil {
+		return err
+	}
+
+	client, err := clientcmd.NewClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	if err := clientcmd.Preflight(cmd, client, gitlab.Requirement{
+		Scopes:  clientcmd.RequiredScopes(cmd),
+		OwnerOf: []string{args[0]},
+	}); err != nil {
+		return err
+	}
+
+	group, err := gitlab.UpdateGroup(client, args[0], options)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Group updated successfully\n")
+	return printGroup(group)
+}
+
+// updateGroupOptions builds the update from flags given on the command line
+func updateGroupOptions(cmd *cobra.Command) (*client.UpdateGroupOptions, error) {
+	flags := cmd.Flags()
+	options := &client.UpdateGroupOptions{}
+	changed := false
+
+	if flags.Changed("name") {
+		name, _ := flags.GetString("name")
+		options.Name = client.Ptr(name)
+		changed = true
+	}
+	if flags.Changed("description") {
+		description, _ := flags.GetString("description")
+		options.Description = client.Ptr(description)
+		changed = true
+	}
+	if flags.Changed("visibility") {
+		visibility, _ := flags.GetString("visibility")
+		switch client.VisibilityValue(visibility) {
+		case client.PrivateVisibility, client.InternalVisibility, client.PublicVisibility:
+		default:
+			return nil, fmt.Errorf("invalid visibility %q (supported: private, internal, public)", visibility)
+		}
+		options.Visibility = client.Ptr(client.VisibilityValue(visibility))
+		changed = true
+	}
+	if flags.Changed("projectCreationLevel") {
+		level, _ := flags.GetString("projectCreationLevel")
+		switch client.ProjectCreationLevelValue(level) {
+		case client.NoOneProjectCreation, client.OwnerProjectCreation, client.MaintainerProjectCreation, client.DeveloperProjectCreation:
+		default:
+			return nil, fmt.Errorf("invalid project creation level %q (supported: noone, owner, maintainer, developer)", level)
+		}
+		options.ProjectCreationLevel = client.Ptr(client.ProjectCreationLevelValue(level))
+		changed = true
+	}
+	if flags.Changed("subgroupCreationLevel") {
+		level, _ := flags.GetString("subgroupCreationLevel")
+		switch client.SubGroupCreationLevelValue(level) {
+		case client.OwnerSubGroupCreationLevelValue, client.MaintainerSubGroupCreationLevelValue:
+		default:
+			return nil, fmt.Errorf("invalid subgroup creation level %q (supported: owner, maintainer)", level)
+		}
+		options.SubGroupCreationLevel = client.Ptr(client.SubGroupCreationLevelValue(level))
+		changed = true
+	}
+	if flags.Changed("requireTwoFactorAuth") {
+		require, _ := flags.GetBool("requireTwoFactorAuth")
+		options.RequireTwoFactorAuth = client.Ptr(require)
+		changed = true
+	}
+	if flags.Changed("twoFactorGracePeriod") {
+		hours, _ := flags.GetInt("twoFactorGracePeriod")
+		options.TwoFactorGracePeriod = client.Ptr(hours)
+		changed = true
+	}
+	if flags.Changed("defaultBranchProtection") {
+		preset, _ := flags.GetString("defaultBranchProtection")
+		protection, err := gitlab.BranchProtectionOptions(preset)
+		if err != nil {
+			return nil, err
+		}
+		options.DefaultBranchProtectionDefaults = protection
+		changed = true
+	}
+
+	if !changed {
+		return nil, fmt.Errorf("nothing to update, set at least one setting flag")
+	}
+	return options, nil
+}
@@ -13,4 +13,5 @@ var GroupSyncCmd = &cobra.Command{
 
 func init() {
 	GroupSyncCmd.AddCommand(LdapCmd)
+	GroupSyncCmd.AddCommand(ServeCmd)
 }
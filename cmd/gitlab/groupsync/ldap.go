@@ -2,20 +2,35 @@ package cmd
 
 import (
 	"fmt"
-	"log"
-	"net/http"
+	"log/slog"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
-	common "github.com/Cloud-for-You/devops-cli/pkg"
-	gitlab "github.com/Cloud-for-You/devops-cli/pkg/gitlab"
+	clientcmd "github.com/Cloud-for-You/devops-cli/cmd/gitlab/clientcmd"
+	groupsync "github.com/Cloud-for-You/devops-cli/pkg/gitlab/groupsync"
 	ldap "github.com/Cloud-for-You/devops-cli/pkg/gitlab/groupsync/ldap"
+	metrics "github.com/Cloud-for-You/devops-cli/pkg/metrics"
+	secret "github.com/Cloud-for-You/devops-cli/pkg/secret"
 	client "gitlab.com/gitlab-org/api/client-go"
 )
 
 var (
-	ldapHost, ldapBindDN, ldapPassword, ldapSearchBase, ldapFilter string
+	ldapHost, ldapBindDN, ldapPassword, ldapFilter string
+	ldapSearchBase, ldapGroupDNs                   []string
+	ldapIncludeGroups, ldapExcludeGroups           []string
+
+	ldapBindMethod, ldapCACert, ldapClientCert, ldapClientKey string
+	ldapUsername, ldapDomain, ldapRealm, ldapKeytab           string
+	ldapKrb5Conf, ldapSPN                                     string
+	ldapStartTLS, ldapInsecure                                bool
+
+	metricsTextfile string
+
+	incrementalMode, stateFile string
+	fullSyncInterval           time.Duration
+	forceFullSync              bool
 )
 
 var LdapCmd = &cobra.Command{
@@ -35,158 +50,281 @@ Examples:
   devops-cli groupsync ldap \
 	--ldapHost "ldaps://secure.example.com" \
 	--ldapBindDN "CN=manager,DC=example,DC=com" \
-	--ldapPassword "LDAP_Password_123" \
+	--ldapPassword "env:LDAP_PASSWORD" \
+	--ldapSearchBase "OU=Groups,DC=example,DC=com" \
+	--gitlabUrl "https://gitlab.example.com" \
+	--gitlabToken "env:GITLAB_TOKEN"
+
+  # Synchronize all GL-* groups from two OUs except the legacy ones
+  devops-cli groupsync ldap \
+	--ldapHost "ldaps://secure.example.com" \
+	--ldapBindDN "CN=manager,DC=example,DC=com" \
+	--ldapPassword "env:LDAP_PASSWORD" \
 	--ldapSearchBase "OU=Groups,DC=example,DC=com" \
+	--ldapSearchBase "OU=Teams,DC=example,DC=com" \
+	--ldapIncludeGroup "^GL-" \
+	--ldapExcludeGroup "^GL-.*-legacy$" \
 	--gitlabUrl "https://gitlab.example.com" \
-	--gitlabToken "2fb5ae578dd22282da6289d1"
+	--gitlabToken "env:GITLAB_TOKEN"
+
+  # Authenticate with Kerberos (GSSAPI) using a keytab
+  devops-cli groupsync ldap \
+	--ldapHost "ldap://dc01.example.com" \
+	--ldapBindMethod gssapi \
+	--ldapUsername "svc-gitlab" \
+	--ldapRealm "EXAMPLE.COM" \
+	--ldapKeytab "/etc/devops-cli/svc-gitlab.keytab" \
+	--ldapSearchBase "OU=Groups,DC=example,DC=com" \
+	--gitlabUrl "https://gitlab.example.com" \
+	--gitlabToken "env:GITLAB_TOKEN"
+
+Secrets (--ldapPassword, --gitlabToken) should not be passed literally, they end up
+in the shell history and in the process list. Use a reference instead:
+  env:VAR          read the value from the environment variable VAR
+  file:/path       read the value from a file
+  vault:path#key   read the key of a HashiCorp Vault KV secret (VAULT_ADDR, VAULT_TOKEN)
+
+Incremental synchronization (--incremental) keeps a watermark in --stateFile and
+reconciles only groups changed since the last run. Mode "timestamp" uses whenChanged
+(Active Directory, always connect to the same domain controller) or modifyTimestamp,
+mode "dirsync" uses the Active Directory DirSync control. A full synchronization is
+done on the first run, when the group selection changes and every --fullSyncInterval.
+
+Supported bind methods (--ldapBindMethod):
+  simple    bind DN and password (default)
+  external  SASL EXTERNAL with a TLS client certificate (--ldapClientCert, --ldapClientKey)
+  ntlm      NTLM with --ldapUsername, --ldapDomain and --ldapPassword
+  gssapi    Kerberos with --ldapUsername, --ldapRealm and --ldapKeytab (or --ldapPassword)
+
+The group selection can be also defined in the configuration file:
+
+  ldapSearchBase:
+    - OU=Groups,DC=example,DC=com
+  ldapGroupDN:
+    - CN=GL-Platform,OU=Special,DC=example,DC=com
+  ldapIncludeGroup:
+    - ^GL-
+  ldapExcludeGroup:
+    - ^GL-.*-legacy$
+
+Teams: instead of copying members of an LDAP group into many GitLab groups, the LDAP
+group is synchronized once into its GitLab group and that "team" group is shared into
+the groups and projects listed in the configuration file. Shares are created and their
+access level updated, shares which are not listed are never removed.
+
+  teams:
+    - group: GL-Backend
+      shareInto:
+        - group: platform/backend
+          accessLevel: developer
+        - project: platform/legacy
+          accessLevel: reporter
 `,
-	Run: ldapGroupSync,
+	RunE:        ldapGroupSync,
+	Annotations: map[string]string{clientcmd.ScopesAnnotation: "api"},
 }
 
 func init() {
 	// GitLab GroupSync LDAP
 	LdapCmd.Flags().StringVarP(&ldapHost, "ldapHost", "H", "", "the IP address or resolvable name to use to connect to the directory server")
 	viper.BindPFlag("ldapHost", LdapCmd.Flags().Lookup("ldapHost"))
+	LdapCmd.Flags().StringVar(&ldapBindMethod, "ldapBindMethod", ldap.BindSimple, "authentication method used to bind to the directory server (simple, external, ntlm, gssapi)")
+	viper.BindPFlag("ldapBindMethod", LdapCmd.Flags().Lookup("ldapBindMethod"))
 	LdapCmd.Flags().StringVarP(&ldapBindDN, "ldapBindDN", "D", "", "the DN to use to bind to the directory server when performing simple authentication")
 	viper.BindPFlag("ldapBindDN", LdapCmd.Flags().Lookup("ldapBindDN"))
-	LdapCmd.Flags().StringVarP(&ldapPassword, "ldapPassword", "W", "", "the password to use to bind to the directory server when performing simple authentication or a password-based SASL mechanism")
+	LdapCmd.Flags().StringVarP(&ldapPassword, "ldapPassword", "W", "", "the password to use to bind to the directory server when performing simple authentication or a password-based SASL mechanism, literal value or reference env:VAR, file:/path, vault:path#key")
 	viper.BindPFlag("ldapPassword", LdapCmd.Flags().Lookup("ldapPassword"))
-	LdapCmd.Flags().StringVarP(&ldapSearchBase, "ldapSearchBase", "b", "", "specifies the base DN that should be used for the search")
+	secret.MarkFlag(LdapCmd.Flags(), "ldapPassword")
+	LdapCmd.Flags().StringVar(&ldapUsername, "ldapUsername", "", "the user name for NTLM bind or the Kerberos principal for GSSAPI bind")
+	viper.BindPFlag("ldapUsername", LdapCmd.Flags().Lookup("ldapUsername"))
+	LdapCmd.Flags().StringVar(&ldapDomain, "ldapDomain", "", "(optional) the AD domain for NTLM bind")
+	viper.BindPFlag("ldapDomain", LdapCmd.Flags().Lookup("ldapDomain"))
+	LdapCmd.Flags().StringVar(&ldapRealm, "ldapRealm", "", "(optional) the Kerberos realm for GSSAPI bind, default realm from krb5.conf is used when empty")
+	viper.BindPFlag("ldapRealm", LdapCmd.Flags().Lookup("ldapRealm"))
+	LdapCmd.Flags().StringVar(&ldapKeytab, "ldapKeytab", "", "the keytab file for GSSAPI bind")
+	viper.BindPFlag("ldapKeytab", LdapCmd.Flags().Lookup("ldapKeytab"))
+	LdapCmd.Flags().StringVar(&ldapKrb5Conf, "ldapKrb5Conf", "/etc/krb5.conf", "the Kerberos configuration file for GSSAPI bind")
+	viper.BindPFlag("ldapKrb5Conf", LdapCmd.Flags().Lookup("ldapKrb5Conf"))
+	LdapCmd.Flags().StringVar(&ldapSPN, "ldapSPN", "", "(optional) the service principal of the directory server, default is ldap/<host>")
+	viper.BindPFlag("ldapSPN", LdapCmd.Flags().Lookup("ldapSPN"))
+	LdapCmd.Flags().BoolVar(&ldapStartTLS, "ldapStartTLS", false, "upgrade an ldap:// connection with StartTLS")
+	viper.BindPFlag("ldapStartTLS", LdapCmd.Flags().Lookup("ldapStartTLS"))
+	LdapCmd.Flags().StringVar(&ldapCACert, "ldapCACert", "", "(optional) PEM bundle with CA certificates used to verify the directory server")
+	viper.BindPFlag("ldapCACert", LdapCmd.Flags().Lookup("ldapCACert"))
+	LdapCmd.Flags().StringVar(&ldapClientCert, "ldapClientCert", "", "the client certificate for SASL EXTERNAL bind")
+	viper.BindPFlag("ldapClientCert", LdapCmd.Flags().Lookup("ldapClientCert"))
+	LdapCmd.Flags().StringVar(&ldapClientKey, "ldapClientKey", "", "the client certificate key for SASL EXTERNAL bind")
+	viper.BindPFlag("ldapClientKey", LdapCmd.Flags().Lookup("ldapClientKey"))
+	LdapCmd.Flags().BoolVar(&ldapInsecure, "ldapInsecureSkipVerify", false, "do not verify the directory server certificate")
+	viper.BindPFlag("ldapInsecureSkipVerify", LdapCmd.Flags().Lookup("ldapInsecureSkipVerify"))
+	// DN i regularni vyrazy obsahuji carky, StringSlice by je rozdelil na casti
+	LdapCmd.Flags().StringArrayVarP(&ldapSearchBase, "ldapSearchBase", "b", nil, "specifies the base DN that should be used for the search (can be repeated)")
 	viper.BindPFlag("ldapSearchBase", LdapCmd.Flags().Lookup("ldapSearchBase"))
 	LdapCmd.Flags().StringVarP(&ldapFilter, "ldapGroupFilter", "f", "(objectClass=group)", "(optional) specified LDAP group search filter")
 	viper.BindPFlag("ldapGroupFilter", LdapCmd.Flags().Lookup("ldapGroupFilter"))
+	LdapCmd.Flags().StringArrayVar(&ldapGroupDNs, "ldapGroupDN", nil, "(optional) DN of a group to synchronize, read directly without search (can be repeated)")
+	viper.BindPFlag("ldapGroupDN", LdapCmd.Flags().Lookup("ldapGroupDN"))
+	LdapCmd.Flags().StringArrayVar(&ldapIncludeGroups, "ldapIncludeGroup", nil, "(optional) regular expression, synchronize only groups whose cn matches (can be repeated)")
+	viper.BindPFlag("ldapIncludeGroup", LdapCmd.Flags().Lookup("ldapIncludeGroup"))
+	LdapCmd.Flags().StringArrayVar(&ldapExcludeGroups, "ldapExcludeGroup", nil, "(optional) regular expression, skip groups whose cn matches (can be repeated)")
+	viper.BindPFlag("ldapExcludeGroup", LdapCmd.Flags().Lookup("ldapExcludeGroup"))
+
+	LdapCmd.Flags().StringVar(&incrementalMode, "incremental", "", "(optional) synchronize only groups changed since the last run: timestamp (whenChanged/modifyTimestamp) or dirsync (Active Directory)")
+	LdapCmd.Flags().StringVar(&stateFile, "stateFile", "groupsync-ldap.state", "file keeping the incremental sync watermark between runs")
+	LdapCmd.Flags().DurationVar(&fullSyncInterval, "fullSyncInterval", 24*time.Hour, "force a full sync when the last one is older, 0 disables it")
+	LdapCmd.Flags().BoolVar(&forceFullSync, "fullSync", false, "ignore the incremental state and synchronize all groups")
+	LdapCmd.Flags().StringVar(&metricsTextfile, "metricsTextfile", "", "(optional) write Prometheus metrics of the run to this file for the node_exporter textfile collector")
 
 	LdapCmd.MarkFlagRequired("ldapHost")
-	LdapCmd.MarkFlagRequired("ldapBindDN")
-	LdapCmd.MarkFlagRequired("ldapPassword")
-	LdapCmd.MarkFlagRequired("ldapSearchBase")
 }
 
-func ldapGroupSync(cmd *cobra.Command, args []string) {
+func ldapGroupSync(cmd *cobra.Command, args []string) error {
 	ldapHost, _ := cmd.Flags().GetString("ldapHost")
 	ldapBindDN, _ := cmd.Flags().GetString("ldapBindDN")
 	ldapPassword, _ := cmd.Flags().GetString("ldapPassword")
-	ldapSearchBase, _ := cmd.Flags().GetString("ldapSearchBase")
 	ldapGroupFilter, _ := cmd.Flags().GetString("ldapGroupFilter")
 
-	gitlabToken, _ := cmd.Flags().GetString("gitlabToken")
-	gitlabUrl, _ := cmd.Flags().GetString("gitlabUrl")
-
-	// Overeni, ze mame gitlabURL a gitlabToken
-	if gitlabToken == "" || gitlabUrl == "" {
-		log.Fatalf("Gitlab token and URL must be provided using the persistent flags --gitlabToken and --gitlabUrl")
+	// Vyber skupin muze prijit i z konfiguracniho souboru
+	selection := ldap.GroupSelection{
+		DNs:     viper.GetStringSlice("ldapGroupDN"),
+		BaseDNs: viper.GetStringSlice("ldapSearchBase"),
+		Filter:  ldapGroupFilter,
+		Include: viper.GetStringSlice("ldapIncludeGroup"),
+		Exclude: viper.GetStringSlice("ldapExcludeGroup"),
+	}
+	if len(selection.DNs) == 0 && len(selection.BaseDNs) == 0 {
+		return fmt.Errorf("at least one --ldapSearchBase or --ldapGroupDN must be provided")
 	}
 
+	// Pozadavky na jednotlive metody overuje ldap.NewLDAPConnector
 	ldapConfig := ldap.LDAPConfig{
-		Host:     ldapHost,
-		BindDN:   ldapBindDN,
-		Password: ldapPassword,
-		BaseDN:   ldapSearchBase,
-	}
-	connector, err := ldap.NewLDAPConnector(ldapConfig)
-	if err != nil {
-		log.Fatalf("ERROR: %v", err)
+		Host:               ldapHost,
+		BindDN:             ldapBindDN,
+		Password:           ldapPassword,
+		BindMethod:         viper.GetString("ldapBindMethod"),
+		StartTLS:           viper.GetBool("ldapStartTLS"),
+		CACert:             viper.GetString("ldapCACert"),
+		ClientCert:         viper.GetString("ldapClientCert"),
+		ClientKey:          viper.GetString("ldapClientKey"),
+		InsecureSkipVerify: viper.GetBool("ldapInsecureSkipVerify"),
+		Username:           viper.GetString("ldapUsername"),
+		Domain:             viper.GetString("ldapDomain"),
+		Realm:              viper.GetString("ldapRealm"),
+		Keytab:             viper.GetString("ldapKeytab"),
+		Krb5Conf:           viper.GetString("ldapKrb5Conf"),
+		ServicePrincipal:   viper.GetString("ldapSPN"),
 	}
-	defer connector.Close()
 
-	groupSyncer := ldap.NewLDAPGroupSyncer(connector, ldapGroupFilter)
+	var teams []groupsync.Team
+	if err := viper.UnmarshalKey("teams", &teams); err != nil {
+		return fmt.Errorf("invalid teams configuration: %w", err)
+	}
 
-	// Nacteni skupin z LDAPu
-	groups, err := groupSyncer.GetLdapGroups()
+	client, err := clientcmd.NewClient(cmd)
 	if err != nil {
-		log.Fatalf("ERROR: %s", err)
+		return err
 	}
 
-	client, err := client.NewClient(gitlabToken, client.WithBaseURL(gitlabUrl))
-	if err != nil {
-		log.Fatalf("Failed to create GitLab client: %v", err)
+	inc := incrementalOptions{
+		Mode:             incrementalMode,
+		StateFile:        stateFile,
+		FullSyncInterval: fullSyncInterval,
+		ForceFull:        forceFullSync,
 	}
-	gitlabWhoami, _ := gitlab.Whoami(client)
 
-	// Iterace pres vsechny LDAP skupiny
-	for _, group := range groups.Entries {
-		groupName := group.GetAttributeValue("cn")
+	preflight := func(plan *groupsync.Plan) error {
+		return clientcmd.Preflight(cmd, client, plan.Requirement(clientcmd.RequiredScopes(cmd)))
+	}
 
-		if gitlabToken == "" || gitlabUrl == "" {
-			log.Fatalf("Gitlab token and URL must be provided using the persistent flags --gitlabToken and --gitlabUrl")
-		}
+	start := time.Now()
+	_, result, err := runLdapSync(client, ldapConfig, selection, teams, inc, preflight, slog.Default())
+	success := err == nil && !result.Failed()
+	metrics.ObserveSyncRun("ldap", "cli", time.Since(start), success)
 
-		// Ziskani seznamu clenu skupiny z LDAPu
-		ldapMembersDNs, err := groupSyncer.ListLdapGroupMemberDNs(group.DN)
-		if err != nil {
-			log.Fatalf("Error listing Ldap group members: %v", err)
+	// Metriky zapiseme i pri chybe, aby byla videt v monitoringu
+	if metricsTextfile != "" {
+		if err := metrics.WriteTextfile(metricsTextfile); err != nil {
+			slog.Error("failed to write metrics", "error", err)
 		}
+	}
 
-		var ldapMembers []common.Member
-		for _, dn := range ldapMembersDNs {
-			// Ziskani atributu clena
-			//attributes := []string{"givenName"}
-			userAttributes, err := connector.GetLdapUserAttributes(dn, nil)
-			if err != nil {
-				log.Fatalf("Error to get attributes for user %s: %v", dn, err)
-			}
-			if len(userAttributes.Entries) > 0 {
-				mail := userAttributes.Entries[0].GetAttributeValue("sAMAccountName")
-				ldapMembers = append(ldapMembers, common.Member{Name: mail})
-			}
-		}
+	if err != nil {
+		return err
+	}
+	if !success {
+		return fmt.Errorf("synchronization finished with errors")
+	}
+	return nil
+}
+
+// incrementalOptions configures incremental synchronization, empty Mode disables it
+type incrementalOptions struct {
+	Mode             string        `mapstructure:"mode"`
+	StateFile        string        `mapstructure:"stateFile"`
+	FullSyncInterval time.Duration `mapstructure:"fullSyncInterval"`
+	ForceFull        bool          `mapstructure:"-"`
+}
+
+// runLdapSync reads LDAP groups and applies the changes including shares of team
+// groups, preflight verifies permissions after the plan is computed and before
+// anything is changed
+func runLdapSync(client *client.Client, ldapConfig ldap.LDAPConfig, selection ldap.GroupSelection, teams []groupsync.Team, inc incrementalOptions, preflight func(*groupsync.Plan) error, logger *slog.Logger) (*groupsync.Plan, *groupsync.Result, error) {
+	var groups []groupsync.Group
+	var state *ldap.SyncState
+	var err error
 
-		// Ziskani clenu skupiny z GitLab
-		gitlabMembersRaw, err := gitlab.ListGitlabGroupMembers(client, groupName)
+	// Nacteni skupin a clenu z LDAPu
+	if inc.Mode == "" {
+		groups, err = ldap.ReadGroups(ldapConfig, selection)
+	} else {
+		if inc.StateFile == "" {
+			return nil, nil, fmt.Errorf("incremental sync requires a state file")
+		}
+		state, err = ldap.LoadSyncState(inc.StateFile)
 		if err != nil {
-			// Zalozime skupinu v GitLabu a vlozime do ni membery
-			_, response, err := gitlab.CreateGroup(client, groupName, "", "private")
-			if err != nil {
-				if response != nil && response.StatusCode == http.StatusConflict {
-					fmt.Printf("Group '%s' is exists.\n", groupName)
-				} else {
-					fmt.Printf("Failed to create GitLab group '%s': %v\n", groupName, err)
-				}
-			}
-			fmt.Printf("Error listing GitLab group members: %v\n", err)
+			return nil, nil, err
 		}
 
-		var gitlabGroupMembers []common.Member
-		for _, member := range gitlabMembersRaw {
-			if member.Username != "root" || member.Username != *gitlabWhoami {
-				gitlabGroupMembers = append(gitlabGroupMembers, common.Member{Name: member.Username})
-			}
+		var full bool
+		groups, full, err = ldap.ReadChangedGroups(ldapConfig, selection, state, ldap.IncrementalConfig{
+			Mode:             inc.Mode,
+			FullSyncInterval: inc.FullSyncInterval,
+			ForceFull:        inc.ForceFull,
+		})
+		if err == nil {
+			logger.Info("incremental sync", "full", full, "groups", len(groups))
 		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
 
-		// Overime zda skupina podle nazvu v GitLabu existuje a pokud ano, pouze ji sesynchronizujeme
-		// a pokracujeme dalsi skupinou
-		group, err := gitlab.GetGroup(client, groupName)
+	groups, err = groupsync.MapTeams(groups, teams)
+	if err != nil {
+		return nil, nil, err
+	}
 
-		if err != nil {
-			//			// Zalozime skupinu v GitLabu a vlozime do ni membery
-			//			_, response, err := gitlab.CreateGroup(client, groupName, "", "private")
-			//			if err != nil {
-			//				if response != nil && response.StatusCode == http.StatusConflict {
-			//					fmt.Printf("Group '%s' is exists.\n", groupName)
-			//				} else {
-			//					fmt.Printf("Failed to create GitLab group '%s': %v\n", groupName, err)
-			//					os.Exit(1)
-			//				}
-			//			}
+	plan, err := groupsync.NewPlan(client, "ldap", groups)
+	if err != nil {
+		return nil, nil, err
+	}
+	logPlan(logger, plan)
+
+	if !plan.Empty() {
+		if err := preflight(plan); err != nil {
+			return plan, nil, err
 		}
+	}
 
-		fmt.Printf("Synchronizing members of an existing GitLab group [%s]\n", group.Name)
+	result := groupsync.Apply(client, plan, logger)
+	logResult(logger, result)
 
-		missing, extra := common.CompareMembers(gitlabGroupMembers, ldapMembers)
-		for _, m := range missing {
-			fmt.Printf("Add members %s to GitLab group %s\n", m.Name, group.Name)
-			err = gitlab.AddMemberToGroup(client, group.Name, m.Name, nil)
-			if err != nil {
-				log.Fatalf("error: %v", err)
-			}
+	// Stav ulozime jen po uspesnem behu, jinak by se neuspesne zmeny priste neopakovaly
+	if state != nil && !result.Failed() {
+		if err := state.Save(inc.StateFile); err != nil {
+			return plan, result, err
 		}
-		for _, m := range extra {
-			fmt.Printf("Remove member %s from GitLab group %s\n", m.Name, group.Name)
-			err = gitlab.RemoveUserFromGroup(client, group.Name, m.Name)
-			if err != nil {
-				log.Fatalf("error: %v", err)
-			}
-		}
-
 	}
+
+	return plan, result, nil
 }
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"log/slog"
+
+	common "github.com/Cloud-for-You/devops-cli/pkg"
+	groupsync "github.com/Cloud-for-You/devops-cli/pkg/gitlab/groupsync"
+)
+
+func logPlan(logger *slog.Logger, plan *groupsync.Plan) {
+	for _, group := range plan.Groups {
+		logger.Info("synchronization plan",
+			"group", group.Name,
+			"create", group.Create,
+			"add", names(group.Changes.Members(common.ChangeAdd)),
+			"remove", names(group.Changes.Members(common.ChangeRemove)),
+			"updateAccess", names(group.Changes.Members(common.ChangeUpdateAccess)),
+			"updateExpiry", names(group.Changes.Members(common.ChangeUpdateExpiry)),
+			"share", shareTargets(group.Shares),
+		)
+	}
+}
+
+func logResult(logger *slog.Logger, result *groupsync.Result) {
+	for _, group := range result.Groups {
+		if group.Error != "" {
+			logger.Error("group was not synchronized", "group", group.Name, "error", group.Error)
+			continue
+		}
+		logger.Info("group synchronized",
+			"group", group.Name,
+			"applied", len(group.Applied),
+			"failed", len(group.Failed),
+			"skipped", len(group.Skipped),
+			"shared", len(group.Shared),
+			"failedShares", len(group.FailedShares),
+		)
+	}
+}
+
+func names(members []common.Member) []string {
+	var out []string
+	for _, m := range members {
+		out = append(out, m.Name)
+	}
+	return out
+}
+
+func shareTargets(shares []groupsync.ShareChange) []string {
+	var out []string
+	for _, s := range shares {
+		out = append(out, s.Target)
+	}
+	return out
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	clientcmd "github.com/Cloud-for-You/devops-cli/cmd/gitlab/clientcmd"
+	groupsync "github.com/Cloud-for-You/devops-cli/pkg/gitlab/groupsync"
+	daemon "github.com/Cloud-for-You/devops-cli/pkg/gitlab/groupsync/daemon"
+	ldap "github.com/Cloud-for-You/devops-cli/pkg/gitlab/groupsync/ldap"
+	logging "github.com/Cloud-for-You/devops-cli/pkg/logging"
+	metrics "github.com/Cloud-for-You/devops-cli/pkg/metrics"
+	secret "github.com/Cloud-for-You/devops-cli/pkg/secret"
+	client "gitlab.com/gitlab-org/api/client-go"
+)
+
+var (
+	listenAddress string
+	runOnStart    bool
+	serveMetrics  bool
+	runToken      string
+)
+
+// syncConfig is one entry of the "syncs" list in the configuration file
+type syncConfig struct {
+	Name     string              `mapstructure:"name"`
+	Schedule string              `mapstructure:"schedule"`
+	LDAP     *ldap.LDAPConfig    `mapstructure:"ldap"`
+	Groups   ldap.GroupSelection `mapstructure:"groups"`
+	Teams    []groupsync.Team    `mapstructure:"teams"`
+
+	Incremental incrementalOptions `mapstructure:"incremental"`
+}
+
+var ServeCmd = &cobra.Command{
+	Use:     "serve",
+	Aliases: []string{"daemon"},
+	Short:   "Run configured synchronizations periodically",
+	Long: `The "serve" command runs as a long-lived process and executes the synchronizations
+defined in the configuration file on an interval or cron schedule. A synchronization is
+never started while its previous run is still in progress.
+
+The last plan and result of every synchronization is kept in memory and exposed over HTTP:
+  GET  /healthz          liveness probe
+  GET  /status           state of all synchronizations
+  GET  /status/<name>    state of one synchronization (503 when the last run failed)
+  POST /run/<name>       start the synchronization immediately, requires
+                         "Authorization: Bearer <runToken>", disabled without --runToken
+  GET  /metrics          Prometheus metrics (with --metrics)
+
+The status contains usernames but no e-mails or LDAP DNs of members. The endpoint
+listens on localhost only by default, use --listen :8080 to expose it, e.g. to
+Kubernetes probes.
+
+Configuration file example:
+
+  gitlabUrl: https://gitlab.example.com
+  gitlabToken: env:GITLAB_TOKEN
+  runToken: env:SYNC_RUN_TOKEN      # optional, enables POST /run/<name>
+  syncs:
+    - name: corporate
+      schedule: "@every 30m"        # or a cron expression, e.g. "0 */2 * * *"
+      ldap:
+        host: ldaps://secure.example.com
+        bindDN: CN=manager,DC=example,DC=com
+        password: env:LDAP_PASSWORD
+      groups:
+        baseDNs:
+          - OU=Groups,DC=example,DC=com
+        include:
+          - ^GL-
+      incremental:                  # optional
+        mode: timestamp             # or dirsync
+        stateFile: /var/lib/devops-cli/corporate.state
+        fullSyncInterval: 24h
+      teams:                        # optional, see "groupsync ldap --help"
+        - group: GL-Backend
+          shareInto:
+            - group: platform/backend
+              accessLevel: developer
+
+Examples:
+  devops-cli gitlab-ce groupsync serve --config /etc/devops-cli/groupsync.yaml --listen :8080 --runToken env:SYNC_RUN_TOKEN
+`,
+	RunE:        serve,
+	Annotations: map[string]string{clientcmd.ScopesAnnotation: "api"},
+}
+
+func init() {
+	ServeCmd.Flags().StringVar(&listenAddress, "listen", "127.0.0.1:8080", "address of the HTTP status endpoint")
+	ServeCmd.Flags().StringVar(&runToken, "runToken", "", "bearer token required by POST /run/<name>, triggering is disabled when empty (env:, file: or vault: reference)")
+	viper.BindPFlag("runToken", ServeCmd.Flags().Lookup("runToken"))
+	secret.MarkFlag(ServeCmd.Flags(), "runToken")
+	ServeCmd.Flags().BoolVar(&runOnStart, "runOnStart", false, "run all synchronizations immediately after start")
+	ServeCmd.Flags().BoolVar(&serveMetrics, "metrics", false, "expose Prometheus metrics on /metrics")
+}
+
+func serve(cmd *cobra.Command, args []string) error {
+	client, err := clientcmd.NewClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	var syncs []syncConfig
+	if err := viper.UnmarshalKey("syncs", &syncs); err != nil {
+		return fmt.Errorf("invalid syncs configuration: %w", err)
+	}
+	if len(syncs) == 0 {
+		return fmt.Errorf("no synchronizations are configured, add a \"syncs\" section to the configuration file")
+	}
+
+	d := daemon.New()
+	for _, s := range syncs {
+		if s.LDAP == nil {
+			return fmt.Errorf("sync '%s' has no source configured", s.Name)
+		}
+		if err := d.Add(daemon.Job{
+			Name:     s.Name,
+			Schedule: s.Schedule,
+			Run:      ldapSyncJob(cmd, client, s),
+		}); err != nil {
+			return err
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	mux := http.NewServeMux()
+	mux.Handle("/", d.Handler(runToken))
+	if serveMetrics {
+		mux.Handle("GET /metrics", metrics.Handler())
+	}
+
+	server := &http.Server{
+		Addr:              listenAddress,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	serverErr := make(chan error, 1)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	d.Start(runOnStart)
+	slog.Info("serving synchronizations", "syncs", len(syncs), "listen", listenAddress)
+
+	select {
+	case <-ctx.Done():
+	case err := <-serverErr:
+		d.Stop(context.Background())
+		return fmt.Errorf("failed to start HTTP server: %w", err)
+	}
+	slog.Info("shutting down, waiting for running synchronizations")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	server.Shutdown(shutdownCtx)
+	d.Stop(shutdownCtx)
+	return nil
+}
+
+// ldapSyncJob returns the function executed on every scheduled run of the sync
+func ldapSyncJob(cmd *cobra.Command, client *client.Client, s syncConfig) daemon.RunFunc {
+	return func() (plan *groupsync.Plan, result *groupsync.Result, err error) {
+		start := time.Now()
+		defer func() {
+			metrics.ObserveSyncRun("ldap", s.Name, time.Since(start), err == nil && !result.Failed())
+		}()
+
+		// Secret resolvujeme pri kazdem behu, aby se projevila jeho rotace
+		config := *s.LDAP
+		password, err := secret.Resolve(config.Password)
+		if err != nil {
+			return nil, nil, err
+		}
+		config.Password = password
+
+		// Opravneni overujeme pri kazdem behu, token mohl mezitim prijit o prava
+		preflight := func(plan *groupsync.Plan) error {
+			return clientcmd.Preflight(cmd, client, plan.Requirement(clientcmd.RequiredScopes(cmd)))
+		}
+
+		// Kazdy beh ma vlastni run_id, aby sly jeho zaznamy dohledat
+		return runLdapSync(client, config, s.Groups, s.Teams, s.Incremental, preflight, logging.ForRun("sync", s.Name))
+	}
+}
@@ -2,11 +2,10 @@ package cmd
 
 import (
 	"fmt"
-	"log"
 
+	clientcmd "github.com/Cloud-for-You/devops-cli/cmd/gitlab/clientcmd"
 	gitlab "github.com/Cloud-for-You/devops-cli/pkg/gitlab"
 	"github.com/spf13/cobra"
-	client "gitlab.com/gitlab-org/api/client-go"
 )
 
 var ListCmd = &cobra.Command{
@@ -20,16 +19,18 @@ var ListCmd = &cobra.Command{
 
 // Get all Project name and ID from Gitlab
 var ListProjectsCmd = &cobra.Command{
-	Use:   "projects",
-	Short: "Get all GitLab project",
-	Run:   listProjects,
+	Use:         "projects",
+	Short:       "Get all GitLab project",
+	RunE:        listProjects,
+	Annotations: map[string]string{clientcmd.ScopesAnnotation: "read_api"},
 }
 
 // Get all Project name and ID from Gitlab
 var ListGroupsCmd = &cobra.Command{
-	Use:   "groups",
-	Short: "List all GitLab groups",
-	Run:   listGroups,
+	Use:         "groups",
+	Short:       "List all GitLab groups",
+	RunE:        listGroups,
+	Annotations: map[string]string{clientcmd.ScopesAnnotation: "read_api"},
 }
 
 func init() {
@@ -37,49 +38,37 @@ func init() {
 	ListCmd.AddCommand(ListGroupsCmd)
 }
 
-func listProjects(cmd *cobra.Command, args []string) {
-	gitlabToken, _ := cmd.Flags().GetString("gitlabToken")
-	gitlabUrl, _ := cmd.Flags().GetString("gitlabUrl")
-
-	if gitlabToken == "" || gitlabUrl == "" {
-		log.Fatalf("Gitlab token and URL must be provided using the persistent flags --gitlabToken and --gitlabUrl")
-	}
-
-	client, err := client.NewClient(gitlabToken, client.WithBaseURL(gitlabUrl))
+func listProjects(cmd *cobra.Command, args []string) error {
+	client, err := clientcmd.NewClient(cmd)
 	if err != nil {
-		log.Fatalf("Failed to create GitLab client: %v", err)
+		return err
 	}
 
 	projects, err := gitlab.ListProjects(client)
 	if err != nil {
-		log.Fatalf("Error retrieving projects :%v", err)
+		return err
 	}
 
 	for _, project := range projects {
 		fmt.Printf("ID: %d, Name: %s\n", project.ID, project.Name)
 	}
+	return nil
 }
 
-func listGroups(cmd *cobra.Command, args []string) {
-	gitlabToken, _ := cmd.Flags().GetString("gitlabToken")
-	gitlabUrl, _ := cmd.Flags().GetString("gitlabUrl")
-
-	if gitlabToken == "" || gitlabUrl == "" {
-		log.Fatalf("Gitlab token and URL must be provided using the persistent flags --gitlabToken and --gitlabUrl")
-	}
-
-	client, err := client.NewClient(gitlabToken, client.WithBaseURL(gitlabUrl))
+func listGroups(cmd *cobra.Command, args []string) error {
+	client, err := clientcmd.NewClient(cmd)
 	if err != nil {
-		log.Fatalf("Failed to create GitLab client: %v", err)
+		return err
 	}
 
 	groups, err := gitlab.ListGroups(client)
 	if err != nil {
-		log.Fatalf("Error retrieving projects :%v", err)
+		return err
 	}
 
 	for _, group := range groups {
 		fmt.Printf("ID: %d, Name: %s\n", group.ID, group.Name)
 	}
+	return nil
 }
 
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/oauth2"
+
+	clientcmd "github.com/Cloud-for-You/devops-cli/cmd/gitlab/clientcmd"
+	gitlab "github.com/Cloud-for-You/devops-cli/pkg/gitlab"
+)
+
+// Metody prihlaseni prikazu login
+const (
+	loginDevice   = "device"
+	loginBrowser  = "browser"
+	loginPassword = "password"
+)
+
+var (
+	loginMethod, loginRedirectURL string
+	loginScopes                   []string
+)
+
+var LoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Log in to GitLab with OAuth2 and store the tokens",
+	Long: `The "login" command obtains OAuth2 access and refresh tokens and stores them in
+~/.config/devops-cli/tokens/<profile>.json (or <host>.json without a profile). Commands
+run with --gitlabAuth oauth2 (or gitlabAuth: oauth2 in the profile) use the stored token
+and refresh it when it expires.
+
+Login methods:
+  device    OAuth2 device authorization grant, open the shown URL on any device and
+            enter the code (GitLab 17.2 or newer)
+  browser   authorization code grant with PKCE, --redirectURL must be registered
+            in the GitLab application
+  password  username and password (--gitlabUsername, --gitlabPassword) exchanged
+            for tokens, intended for the initial bootstrap
+
+device and browser require an OAuth2 application (Admin > Applications or
+User settings > Applications) whose ID is given by --gitlabOAuthClientID.`,
+	Example: `  devops-cli gitlab-ce login --profile prod --gitlabOAuthClientID 4f1c...
+
+  devops-cli gitlab-ce login --profile prod --method password \
+	--gitlabUsername admin --gitlabPassword env:GITLAB_ADMIN_PASSWORD`,
+	Args: cobra.NoArgs,
+	RunE: login,
+}
+
+var LogoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Remove OAuth2 tokens stored by login",
+	Args:  cobra.NoArgs,
+	RunE:  logout,
+}
+
+func init() {
+	LoginCmd.Flags().StringVar(&loginMethod, "method", loginDevice, "login method: device, browser or password")
+	LoginCmd.Flags().StringSliceVar(&loginScopes, "scopes", []string{"api"}, "requested OAuth2 scopes")
+	LoginCmd.Flags().StringVar(&loginRedirectURL, "redirectURL", "http://127.0.0.1:7890/callback", "redirect URL of the browser method")
+}
+
+func login(cmd *cobra.Command, args []string) error {
+	options, err := clientcmd.ConnectionOptions(cmd)
+	if err != nil {
+		return err
+	}
+	tokenFile, err := clientcmd.TokenFile(cmd, options.URL)
+	if err != nil {
+		return err
+	}
+	httpClient, err := gitlab.HTTPClient(options)
+	if err != nil {
+		return err
+	}
+	config, err := gitlab.OAuthConfig(options.URL, options.OAuthClientID, loginScopes)
+	if err != nil {
+		return err
+	}
+
+	if loginMethod != loginPassword && options.OAuthClientID == "" {
+		return fmt.Errorf("%s login requires OAuth2 application ID (--gitlabOAuthClientID)", loginMethod)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	var token *oauth2.Token
+	switch loginMethod {
+	case loginDevice:
+		token, err = gitlab.DeviceLogin(ctx, config, httpClient, func(response *oauth2.DeviceAuthResponse) {
+			uri := response.VerificationURIComplete
+			if uri == "" {
+				uri = response.VerificationURI
+			}
+			fmt.Printf("Open %s and enter the code %s\n", uri, response.UserCode)
+		})
+	case loginBrowser:
+		config.RedirectURL = loginRedirectURL
+		token, err = gitlab.BrowserLogin(ctx, config, httpClient, func(authURL string) {
+			fmt.Printf("Open the following URL in your browser:\n%s\n", authURL)
+		})
+	case loginPassword:
+		if options.Username == "" || options.Password == "" {
+			return fmt.Errorf("password login requires --gitlabUsername and --gitlabPassword")
+		}
+		token, err = gitlab.PasswordLogin(ctx, config, httpClient, options.Username, options.Password)
+	default:
+		return fmt.Errorf("unsupported login method %q (supported: %s, %s, %s)", loginMethod, loginDevice, loginBrowser, loginPassword)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = gitlab.SaveToken(tokenFile, &gitlab.StoredToken{
+		URL:      options.URL,
+		ClientID: options.OAuthClientID,
+		Token:    token,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Overime, ze token funguje
+	configured := options.AuthMethod
+	options.AuthMethod = gitlab.AuthOAuth2
+	options.Token = ""
+	options.TokenFile = tokenFile
+	client, err := gitlab.NewClient(options)
+	if err != nil {
+		return err
+	}
+	username, err := gitlab.Whoami(client)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("logged in", "user", *username, "tokenFile", tokenFile)
+	if configured != gitlab.AuthOAuth2 {
+		slog.Info("use --gitlabAuth oauth2 or gitlabAuth: oauth2 in the profile to authenticate with the stored token")
+	}
+	return nil
+}
+
+func logout(cmd *cobra.Command, args []string) error {
+	options, err := clientcmd.ConnectionOptions(cmd)
+	if err != nil {
+		return err
+	}
+	tokenFile, err := clientcmd.TokenFile(cmd, options.URL)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(tokenFile); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return gitlab.ErrNotLoggedIn
+		}
+		return fmt.Errorf("error removing token file: %w", err)
+	}
+
+	slog.Info("logged out", "tokenFile", tokenFile)
+	return nil
+}
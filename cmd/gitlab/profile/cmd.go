@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	clientcmd "github.com/Cloud-for-You/devops-cli/cmd/gitlab/clientcmd"
+	secret "github.com/Cloud-for-You/devops-cli/pkg/secret"
+)
+
+var (
+	profile    clientcmd.Profile
+	useProfile bool
+	overwrite  bool
+)
+
+var ProfileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Managing GitLab instance profiles",
+	Long: `Profiles are named GitLab instances stored in the configuration file, similar to
+kubeconfig contexts. The selected profile provides URL, token, TLS settings and the
+default namespace, flags given on the command line take precedence.
+
+The profile is selected by --profile, then by $DEVOPS_CLI_PROFILE and finally by
+currentProfile set with "profile use".
+
+Configuration file example:
+
+  currentProfile: prod
+  profiles:
+    prod:
+      gitlabUrl: https://gitlab.example.com
+      gitlabToken: env:GITLAB_PROD_TOKEN
+      gitlabCACert: /etc/pki/corporate-ca.pem
+      namespace: platform
+    staging:
+      gitlabUrl: https://gitlab-staging.example.com
+      gitlabToken: vault:secret/gitlab/staging#token
+    customer:
+      gitlabUrl: https://gitlab.customer.example
+      gitlabAuth: oauth2             # tokens stored by "gitlab-ce login"
+      gitlabOAuthClientID: 4f1c0d...
+`,
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var AddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add or replace a profile",
+	Example: `  devops-cli gitlab-ce profile add prod \
+	--url https://gitlab.example.com \
+	--token env:GITLAB_PROD_TOKEN \
+	--namespace platform \
+	--use`,
+	Args: cobra.ExactArgs(1),
+	RunE: addProfile,
+}
+
+var ListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List profiles, the selected one is marked with *",
+	Args:    cobra.NoArgs,
+	RunE:    listProfiles,
+}
+
+var UseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Select the default profile",
+	Args:  cobra.ExactArgs(1),
+	RunE:  useProfileCmd,
+}
+
+var RemoveCmd = &cobra.Command{
+	Use:     "remove <name>",
+	Aliases: []string{"rm"},
+	Short:   "Remove a profile",
+	Args:    cobra.ExactArgs(1),
+	RunE:    removeProfile,
+}
+
+func init() {
+	ProfileCmd.AddCommand(AddCmd)
+	ProfileCmd.AddCommand(ListCmd)
+	ProfileCmd.AddCommand(UseCmd)
+	ProfileCmd.AddCommand(RemoveCmd)
+
+	// Token se uklada jako reference, proto neni oznacen jako secret a neresolvuje se
+	AddCmd.Flags().StringVar(&profile.GitlabURL, "url", "", "GitLab URL (required)")
+	AddCmd.Flags().StringVar(&profile.GitlabAuth, "auth", "", "authentication method: token (default), oauth2, job-token or basic")
+	AddCmd.Flags().StringVar(&profile.GitlabToken, "token", "", "token reference env:VAR, file:/path or vault:path#key")
+	AddCmd.Flags().StringVar(&profile.GitlabUsername, "username", "", "username of the basic authentication")
+	AddCmd.Flags().StringVar(&profile.GitlabPassword, "password", "", "password reference of the basic authentication")
+	AddCmd.Flags().StringVar(&profile.GitlabOAuthClientID, "clientID", "", "OAuth2 application ID used by login and token refresh")
+	AddCmd.Flags().StringVar(&profile.CACert, "caCert", "", "PEM bundle with CA certificates of the instance")
+	AddCmd.Flags().StringVar(&profile.ClientCert, "clientCert", "", "client certificate for mutual TLS")
+	AddCmd.Flags().StringVar(&profile.ClientKey, "clientKey", "", "private key of the client certificate")
+	AddCmd.Flags().BoolVar(&profile.InsecureSkipVerify, "insecureSkipVerify", false, "do not verify TLS certificate (testing only)")
+	AddCmd.Flags().StringVar(&profile.Proxy, "proxy", "", "HTTP(S) proxy URL")
+	AddCmd.Flags().StringVar(&profile.Namespace, "namespace", "", "default namespace (ID or full path) of new projects")
+	AddCmd.Flags().BoolVar(&useProfile, "use", false, "select the profile as default")
+	AddCmd.Flags().BoolVar(&overwrite, "force", false, "replace an existing profile")
+
+	AddCmd.MarkFlagRequired("url")
+}
+
+func addProfile(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if config.hasProfile(name) && !overwrite {
+		return fmt.Errorf("profile %q already exists, use --force to replace it", name)
+	}
+
+	for flag, value := range map[string]string{"token": profile.GitlabToken, "password": profile.GitlabPassword} {
+		if value != "" && !secret.IsReference(value) {
+			slog.Warn(flag+" is stored in plain text, use a reference env:, file: or vault: instead", "profile", name)
+		}
+	}
+
+	if err := config.setProfile(name, profile); err != nil {
+		return err
+	}
+	if useProfile {
+		config.setCurrentProfile(name)
+	}
+	if err := config.save(); err != nil {
+		return err
+	}
+
+	slog.Info("profile saved", "profile", name, "file", config.path)
+	return nil
+}
+
+func listProfiles(cmd *cobra.Command, args []string) error {
+	profiles, err := clientcmd.Profiles()
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	current := clientcmd.ProfileName(cmd)
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "CURRENT\tNAME\tURL\tNAMESPACE")
+	for _, name := range names {
+		marker := ""
+		if name == current {
+			marker = "*"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", marker, name, profiles[name].GitlabURL, profiles[name].Namespace)
+	}
+	return w.Flush()
+}
+
+func useProfileCmd(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if !config.hasProfile(name) {
+		return fmt.Errorf("profile %q not found in %s", name, config.path)
+	}
+
+	config.setCurrentProfile(name)
+	if err := config.save(); err != nil {
+		return err
+	}
+
+	slog.Info("default profile changed", "profile", name)
+	return nil
+}
+
+func removeProfile(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if !config.removeProfile(name) {
+		return fmt.Errorf("profile %q not found in %s", name, config.path)
+	}
+	if err := config.save(); err != nil {
+		return err
+	}
+
+	slog.Info("profile removed", "profile", name)
+	return nil
+}
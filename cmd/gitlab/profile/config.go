@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+
+	clientcmd "github.com/Cloud-for-You/devops-cli/cmd/gitlab/clientcmd"
+)
+
+// configFile is the YAML configuration file edited by the profile commands.
+// It is edited as a node tree, so comments and order of other keys are kept.
+type configFile struct {
+	path string
+	doc  yaml.Node
+}
+
+// configPath returns the configuration file of this run or the default one
+func configPath() (string, error) {
+	if path := viper.ConfigFileUsed(); path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine configuration file location: %w", err)
+	}
+	return filepath.Join(home, ".config", "devops-cli", "devops-cli.yaml"), nil
+}
+
+func loadConfig() (*configFile, error) {
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+	if ext := strings.ToLower(filepath.Ext(path)); ext != ".yaml" && ext != ".yml" {
+		return nil, fmt.Errorf("profiles can be edited only in a YAML configuration file, not %s", path)
+	}
+
+	config := &configFile{path: path}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error reading configuration file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &config.doc); err != nil {
+		return nil, fmt.Errorf("error parsing configuration file %s: %w", path, err)
+	}
+
+	// Prazdny nebo neexistujici soubor
+	if config.doc.Kind == 0 {
+		config.doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	if config.root().Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("configuration file %s is not a YAML mapping", path)
+	}
+
+	return config, nil
+}
+
+func (c *configFile) root() *yaml.Node {
+	return c.doc.Content[0]
+}
+
+// save writes the file atomically, it may contain tokens so it is readable only by the owner
+func (c *configFile) save() error {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&c.doc); err != nil {
+		return fmt.Errorf("error encoding configuration: %w", err)
+	}
+	encoder.Close()
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return fmt.Errorf("error creating configuration directory: %w", err)
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("error writing configuration file: %w", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("error writing configuration file: %w", err)
+	}
+	return nil
+}
+
+// profiles returns the "profiles" mapping, create adds it when missing
+func (c *configFile) profiles(create bool) *yaml.Node {
+	profiles := mappingValue(c.root(), "profiles")
+	if profiles == nil && create {
+		profiles = &yaml.Node{Kind: yaml.MappingNode}
+		setMappingValue(c.root(), "profiles", profiles)
+	}
+	return profiles
+}
+
+func (c *configFile) hasProfile(name string) bool {
+	profiles := c.profiles(false)
+	return profiles != nil && mappingValue(profiles, name) != nil
+}
+
+func (c *configFile) setProfile(name string, profile clientcmd.Profile) error {
+	var node yaml.Node
+	if err := node.Encode(profile); err != nil {
+		return fmt.Errorf("error encoding profile: %w", err)
+	}
+	setMappingValue(c.profiles(true), name, &node)
+	return nil
+}
+
+func (c *configFile) removeProfile(name string) bool {
+	profiles := c.profiles(false)
+	if profiles == nil || !deleteMappingKey(profiles, name) {
+		return false
+	}
+	if current := mappingValue(c.root(), "currentProfile"); current != nil && current.Value == name {
+		deleteMappingKey(c.root(), "currentProfile")
+	}
+	return true
+}
+
+func (c *configFile) setCurrentProfile(name string) {
+	setMappingValue(c.root(), "currentProfile", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name})
+}
+
+// mappingValue returns the value node of key, nil when the key is missing
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func setMappingValue(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+func deleteMappingKey(mapping *yaml.Node, key string) bool {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return true
+		}
+	}
+	return false
+}
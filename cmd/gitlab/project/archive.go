@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"github.com/spf13/cobra"
+
+	clientcmd "github.com/Cloud-for-You/devops-cli/cmd/gitlab/clientcmd"
+	gitlab "github.com/Cloud-for-You/devops-cli/pkg/gitlab"
+	client "gitlab.com/gitlab-org/api/client-go"
+)
+
+// lifecycleDryRun is the --dryRun flag of archive, unarchive, delete, restore, transfer and fork
+var lifecycleDryRun bool
+
+// Archive GitLab projects
+var ArchiveCmd = &cobra.Command{
+	Use:   "archive [project...]",
+	Short: "Archive GitLab projects",
+	Long: `The "archive" command makes the projects read-only, archived projects are skipped.
+` + selectHelp,
+	Example: `  devops-cli gitlab-ce project archive platform/legacy
+  devops-cli gitlab-ce project archive --group platform/retired --dryRun`,
+	RunE:        archiveProjects,
+	Annotations: map[string]string{clientcmd.ScopesAnnotation: "api"},
+}
+
+// Unarchive GitLab projects
+var UnarchiveCmd = &cobra.Command{
+	Use:   "unarchive [project...]",
+	Short: "Unarchive GitLab projects",
+	Long: `The "unarchive" command makes archived projects writable again.
+` + selectHelp,
+	Example:     `  devops-cli gitlab-ce project unarchive -f projects.txt`,
+	RunE:        unarchiveProjects,
+	Annotations: map[string]string{clientcmd.ScopesAnnotation: "api"},
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{ArchiveCmd, UnarchiveCmd} {
+		cmd.Flags().BoolVar(&lifecycleDryRun, "dryRun", false, "show projects which would change without changing them")
+		addSelectFlags(cmd)
+	}
+}
+
+func archiveProjects(cmd *cobra.Command, args []string) error {
+	return setArchived(cmd, args, true)
+}
+
+func unarchiveProjects(cmd *cobra.Command, args []string) error {
+	return setArchived(cmd, args, false)
+}
+
+func setArchived(cmd *cobra.Command, args []string, archive bool) error {
+	client, err := clientcmd.NewClient(cmd)
+	if err != nil {
+		return err
+	}
+	if !lifecycleDryRun {
+		if err := clientcmd.Preflight(cmd, client, gitlab.Requirement{Scopes: clientcmd.RequiredScopes(cmd)}); err != nil {
+			return err
+		}
+	}
+
+	// Projekty uz ve spravnem stavu se preskoci, opakovane spusteni nic nezmeni
+	projects, err := selectProjects(client, args)
+	if err != nil {
+		return err
+	}
+	return changeArchived(client, projects, archive)
+}
+
+func changeArchived(glClient *client.Client, projects []*client.Project, archive bool) error {
+	action, change := "archived", gitlab.ArchiveProject
+	if !archive {
+		action, change = "unarchived", gitlab.UnarchiveProject
+	}
+	skip := func(project *client.Project) string {
+		if project.Archived == archive {
+			return "already " + action
+		}
+		return ""
+	}
+	return runProjects(projects, action, lifecycleDryRun, skip, func(project *client.Project) error {
+		if _, err := change(glClient, project.PathWithNamespace); err != nil {
+			return err
+		}
+		slog.Info("project "+action, "project", project.PathWithNamespace)
+		return nil
+	})
+}
@@ -15,4 +15,13 @@ var RepositoryCmd = &cobra.Command{
 
 func init() {
 	RepositoryCmd.AddCommand(CreateCmd)
+	RepositoryCmd.AddCommand(UpdateCmd)
+	RepositoryCmd.AddCommand(ArchiveCmd)
+	RepositoryCmd.AddCommand(UnarchiveCmd)
+	RepositoryCmd.AddCommand(DeleteCmd)
+	RepositoryCmd.AddCommand(RestoreCmd)
+	RepositoryCmd.AddCommand(TransferCmd)
+	RepositoryCmd.AddCommand(ForkCmd)
+	RepositoryCmd.AddCommand(ShareCmd)
+	RepositoryCmd.AddCommand(UnshareCmd)
 }
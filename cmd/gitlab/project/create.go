@@ -2,66 +2,244 @@ package cmd
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 
+	clientcmd "github.com/Cloud-for-You/devops-cli/cmd/gitlab/clientcmd"
 	gitlab "github.com/Cloud-for-You/devops-cli/pkg/gitlab"
 	"github.com/spf13/cobra"
 	client "gitlab.com/gitlab-org/api/client-go"
 )
 
 var (
-	projectName        string
-	projectDescription string
-	namespaceID        int
-	visibility         string
+	projectName         string
+	projectDescription  string
+	namespacePath       string
+	visibility          string
 	maintainerGroupName string
-  developerGroupName string	
+	developerGroupName  string
+	shareGroups         []string
+
+	templateName         string
+	templateGroup        string
+	customTemplate       bool
+	importURL            string
+	initializeWithReadme bool
+	defaultBranch        string
+	scaffoldDir          string
+	scaffoldMessage      string
 )
 
 // Create GitLab repository
 var CreateCmd = &cobra.Command{
 	Use:                   "create",
 	Short:                 "Create GitLab repository",
+	Long: `The "create" command creates the repository and shares it with groups, members
+of a group get at most the given access level in the project. --maintainerGroup and
+--developerGroup are shortcuts for --share group=maintainer and --share group=developer.
+Groups are given by full path and must exist, otherwise nothing is created.
+
+--namespace is the full path of a group or a username, without it the repository is
+created in the namespace of the default profile or in the personal namespace.
+
+Initial content (only one of them):
+  --template              built-in template (e.g. express, spring), --customTemplate uses
+                          an instance template, --templateGroup a template of the group
+  --importUrl             import the repository from a Git URL, the import runs in background
+  --initializeWithReadme  create the default branch with a README
+  --scaffold              commit all files of a local directory (e.g. .gitlab-ci.yml,
+                          CODEOWNERS, renovate.json) as the first commit`,
+	Example: `  devops-cli gitlab-ce project create --name billing --namespace platform/backend \
+	--maintainerGroup platform/backend-leads \
+	--developerGroup platform/backend \
+	--share platform/qa=reporter
+
+  devops-cli gitlab-ce project create --name billing --namespace platform/backend \
+	--defaultBranch main --scaffold ./templates/service
+
+  devops-cli gitlab-ce project create --name billing --namespace platform/backend \
+	--template service-template --templateGroup platform/templates`,
 	DisableFlagsInUseLine: true,
-	Run:                   createRepository,
+	RunE:                  createRepository,
+	Annotations:           map[string]string{clientcmd.ScopesAnnotation: "api"},
 }
 
 func init() {
 	CreateCmd.Flags().StringVar(&projectName, "name", "", "Name of the repository (required)")
 	CreateCmd.Flags().StringVar(&projectDescription, "description", "", "Description of the repository")
-	CreateCmd.Flags().IntVar(&namespaceID, "namespace", 0, "Namespace ID under which the repository will be created")
+	CreateCmd.Flags().StringVar(&namespacePath, "namespace", "", "Full path of the group, username or ID of the namespace, personal namespace when empty")
 	CreateCmd.Flags().StringVar(&visibility, "visibility", "private", "Visibility of the repository (private, internal, public)")
-	CreateCmd.Flags().StringVar(&maintainerGroupName, "maintainerGroup", "", "Group containing maintainers")
-	CreateCmd.Flags().StringVar(&developerGroupName, "developerGroup", "", "Group containing developers")
+	CreateCmd.Flags().StringVar(&maintainerGroupName, "maintainerGroup", "", "Full path of the group containing maintainers")
+	CreateCmd.Flags().StringVar(&developerGroupName, "developerGroup", "", "Full path of the group containing developers")
+	CreateCmd.Flags().StringArrayVar(&shareGroups, "share", nil, "Share the repository with a group as group=level, e.g. platform/qa=reporter (can be repeated)")
+
+	CreateCmd.Flags().StringVar(&templateName, "template", "", "Create the repository from a template")
+	CreateCmd.Flags().StringVar(&templateGroup, "templateGroup", "", "Full path of the group with the --template project")
+	CreateCmd.Flags().BoolVar(&customTemplate, "customTemplate", false, "--template is an instance template instead of a built-in one")
+	CreateCmd.Flags().StringVar(&importURL, "importUrl", "", "Import the repository from a Git URL")
+	CreateCmd.Flags().BoolVar(&initializeWithReadme, "initializeWithReadme", false, "Create the default branch with a README")
+	CreateCmd.Flags().StringVar(&defaultBranch, "defaultBranch", "", "Name of the default branch")
+	CreateCmd.Flags().StringVar(&scaffoldDir, "scaffold", "", "Directory whose files are committed as the first commit")
+	CreateCmd.Flags().StringVar(&scaffoldMessage, "scaffoldMessage", "Initial commit", "Message of the --scaffold commit")
 
 	CreateCmd.MarkFlagRequired("name")
+	CreateCmd.MarkFlagsMutuallyExclusive("template", "importUrl", "initializeWithReadme", "scaffold")
+	CreateCmd.MarkFlagsMutuallyExclusive("templateGroup", "customTemplate")
 }
 
-func createRepository(cmd *cobra.Command, args []string) {
-	gitlabToken, _ := cmd.Flags().GetString("gitlabToken")
-	gitlabUrl, _ := cmd.Flags().GetString("gitlabUrl")
+func createRepository(cmd *cobra.Command, args []string) error {
+	shares, err := projectShares()
+	if err != nil {
+		return err
+	}
+	options, err := createProjectOptions()
+	if err != nil {
+		return err
+	}
 
-	if gitlabToken == "" || gitlabUrl == "" {
-		log.Fatalf("Gitlab token and URL must be provided using the persistent flags --gitlabToken and --gitlabUrl")
+	// Soubory nacteme pred zalozenim projektu, chyba nesmi nechat prazdny projekt
+	var scaffold []*client.CommitActionOptions
+	if scaffoldDir != "" {
+		if scaffold, err = gitlab.ScaffoldActions(scaffoldDir); err != nil {
+			return err
+		}
 	}
 
-	client, err := client.NewClient(gitlabToken, client.WithBaseURL(gitlabUrl))
+	client, err := clientcmd.NewClient(cmd)
 	if err != nil {
-		log.Fatalf("Failed to create GitLab client: %v", err)
+		return err
+	}
+
+	// Bez --namespace pouzijeme vychozi namespace z profilu
+	if !cmd.Flags().Changed("namespace") {
+		profile, err := clientcmd.ActiveProfile(cmd)
+		if err != nil {
+			return err
+		}
+		if profile != nil {
+			namespacePath = profile.Namespace
+		}
 	}
 
-	result, res, err := gitlab.CreateProject(client, projectName, namespaceID, projectDescription, visibility, &maintainerGroupName, &developerGroupName) 
+	// Bez namespace ho API vubec nedostane a projekt vznikne v osobnim namespace
+	namespace := ""
+	if namespacePath != "" {
+		ns, err := gitlab.GetNamespace(client, namespacePath)
+		if err != nil {
+			if gitlab.IsNotFound(err) {
+				return fmt.Errorf("namespace '%s' does not exist or is not visible to the token, use the full path of a group or a username", namespacePath)
+			}
+			return err
+		}
+		options.NamespaceID = &ns.ID
+		namespace = ns.FullPath
+	}
+
+	err = clientcmd.Preflight(cmd, client, gitlab.Requirement{
+		Scopes:          clientcmd.RequiredScopes(cmd),
+		CreateProjectIn: []string{namespace},
+	})
 	if err != nil {
-		if res != nil && res.StatusCode == http.StatusConflict {
-			fmt.Printf("Project '%s' is exists.\n", projectName)
-		} else {
-			fmt.Printf("Failed to create GitLab project '%s': %v\n", projectName, err)
+		return err
+	}
+
+	if templateGroup != "" {
+		group, err := gitlab.GetGroup(client, templateGroup)
+		if err != nil {
+			return fmt.Errorf("failed to get template group '%s': %w", templateGroup, err)
 		}
-	} else {
-		fmt.Printf("Project created successfully\n")
-		fmt.Printf("Name: %s\n", result.Name)
-		fmt.Printf("Description: %s\n", result.Description)
+		options.GroupWithProjectTemplatesID = &group.ID
+	}
+
+	result, res, err := gitlab.CreateProjectWithShares(client, options, shares)
+	if err != nil && result != nil {
+		// Projekt vznikl, selhalo az sdileni se skupinou
 		fmt.Printf("Web URL: %s\n", result.WebURL)
+		return err
+	}
+	if err != nil {
+		if res != nil && res.StatusCode == http.StatusConflict {
+			slog.Warn("project already exists", "project", projectName)
+			return nil
+		}
+		return fmt.Errorf("failed to create GitLab project '%s': %w", projectName, err)
+	}
+
+	if scaffold != nil {
+		// Prazdny repozitar nema vychozi vetev, commit ji zalozi
+		branch := defaultBranch
+		if branch == "" {
+			branch = result.DefaultBranch
+		}
+		if branch == "" {
+			branch = "main"
+		}
+		commit, err := gitlab.CommitScaffold(client, result.PathWithNamespace, branch, scaffoldMessage, scaffold)
+		if err != nil {
+			fmt.Printf("Web URL: %s\n", result.WebURL)
+			return fmt.Errorf("project %s was created, but %w", result.PathWithNamespace, err)
+		}
+		slog.Info("scaffold committed", "project", result.PathWithNamespace, "branch", branch, "commit", commit.ShortID, "files", len(scaffold))
+	}
+
+	fmt.Printf("Project created successfully\n")
+	fmt.Printf("Name: %s\n", result.Name)
+	fmt.Printf("Description: %s\n", result.Description)
+	fmt.Printf("Web URL: %s\n", result.WebURL)
+	if importURL != "" {
+		fmt.Printf("Import status: %s\n", result.ImportStatus)
+	}
+	for _, share := range shares {
+		fmt.Printf("Shared with: %s (%s)\n", share.Group, gitlab.AccessLevelName(share.AccessLevel))
+	}
+	return nil
+}
+
+// createProjectOptions builds the project from flags, the namespace is set later
+func createProjectOptions() (*client.CreateProjectOptions, error) {
+	if (templateGroup != "" || customTemplate) && templateName == "" {
+		return nil, fmt.Errorf("--templateGroup and --customTemplate require --template")
+	}
+
+	options := &client.CreateProjectOptions{
+		Name:        client.Ptr(projectName),
+		Path:        client.Ptr(projectName),
+		Description: client.Ptr(projectDescription),
+		Visibility:  client.Ptr(client.VisibilityValue(visibility)),
+	}
+	if templateName != "" {
+		options.TemplateName = client.Ptr(templateName)
+		if templateGroup != "" || customTemplate {
+			options.UseCustomTemplate = client.Ptr(true)
+		}
+	}
+	if importURL != "" {
+		options.ImportURL = client.Ptr(importURL)
+	}
+	if initializeWithReadme {
+		options.InitializeWithReadme = client.Ptr(true)
+	}
+	if defaultBranch != "" {
+		options.DefaultBranch = client.Ptr(defaultBranch)
+	}
+	return options, nil
+}
+
+// projectShares returns groups from --maintainerGroup, --developerGroup and --share
+func projectShares() ([]gitlab.GroupShare, error) {
+	var shares []gitlab.GroupShare
+	if maintainerGroupName != "" {
+		shares = append(shares, gitlab.GroupShare{Group: maintainerGroupName, AccessLevel: client.MaintainerPermissions})
+	}
+	if developerGroupName != "" {
+		shares = append(shares, gitlab.GroupShare{Group: developerGroupName, AccessLevel: client.DeveloperPermissions})
+	}
+	for _, value := range shareGroups {
+		share, err := gitlab.ParseGroupShare(value)
+		if err != nil {
+			return nil, err
+		}
+		shares = append(shares, share)
 	}
+	return shares, nil
 }
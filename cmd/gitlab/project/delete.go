@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	clientcmd "github.com/Cloud-for-You/devops-cli/cmd/gitlab/clientcmd"
+	gitlab "github.com/Cloud-for-You/devops-cli/pkg/gitlab"
+	client "gitlab.com/gitlab-org/api/client-go"
+)
+
+var permanentlyRemove bool
+
+// Delete GitLab projects
+var DeleteCmd = &cobra.Command{
+	Use:   "delete [project...]",
+	Short: "Delete GitLab projects",
+	Long: `The "delete" command asks to type the full path of the project, or the number of
+projects when more of them are selected, before deleting them. --yes skips the
+question and is required when the project list is read from standard input.
+` + selectHelp + `
+
+When delayed deletion is enabled on the instance, the projects are only marked for
+deletion and can be restored with "project restore". --permanentlyRemove deletes
+projects which are already marked immediately.`,
+	Example: `  devops-cli gitlab-ce project delete platform/legacy
+  devops-cli gitlab-ce project delete --group platform/retired --dryRun
+  devops-cli gitlab-ce project delete -f retired.txt --permanentlyRemove --yes`,
+	RunE:        deleteProjects,
+	Annotations: map[string]string{clientcmd.ScopesAnnotation: "api"},
+}
+
+// Restore GitLab projects marked for deletion
+var RestoreCmd = &cobra.Command{
+	Use:   "restore [project...]",
+	Short: "Restore GitLab projects marked for deletion",
+	Long: `The "restore" command restores projects marked for deletion, other projects are skipped.
+` + selectHelp,
+	Example:     `  devops-cli gitlab-ce project restore --group platform/retired`,
+	RunE:        restoreProjects,
+	Annotations: map[string]string{clientcmd.ScopesAnnotation: "api"},
+}
+
+func init() {
+	DeleteCmd.Flags().BoolVar(&permanentlyRemove, "permanentlyRemove", false, "immediately delete projects already marked for deletion")
+	clientcmd.AddConfirmFlag(DeleteCmd.Flags())
+
+	for _, cmd := range []*cobra.Command{DeleteCmd, RestoreCmd} {
+		cmd.Flags().BoolVar(&lifecycleDryRun, "dryRun", false, "show projects which would change without changing them")
+		addSelectFlags(cmd)
+	}
+}
+
+func deleteProjects(cmd *cobra.Command, args []string) error {
+	client, err := clientcmd.NewClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	projects, err := selectProjects(client, args)
+	if err != nil {
+		return err
+	}
+	if !lifecycleDryRun {
+		// Projekt muze smazat jen vlastnik
+		if err := clientcmd.Preflight(cmd, client, gitlab.Requirement{
+			Scopes:         clientcmd.RequiredScopes(cmd),
+			ProjectOwnerOf: projectPaths(projects),
+		}); err != nil {
+			return err
+		}
+		if err := confirmProjects(cmd, "deleted", projects); err != nil {
+			return err
+		}
+	}
+	return deleteSelected(client, projects)
+}
+
+func deleteSelected(glClient *client.Client, projects []*client.Project) error {
+	skip := func(project *client.Project) string {
+		if permanentlyRemove && project.MarkedForDeletionAt == nil {
+			return "not marked for deletion, delete it first without --permanentlyRemove"
+		}
+		if !permanentlyRemove && project.MarkedForDeletionAt != nil {
+			return "already marked for deletion, use --permanentlyRemove to delete it now"
+		}
+		return ""
+	}
+	return runProjects(projects, "deleted", lifecycleDryRun, skip, func(project *client.Project) error {
+		markedAt, err := gitlab.DeleteProject(glClient, project.PathWithNamespace, permanentlyRemove)
+		if err != nil {
+			return err
+		}
+		if markedAt != nil {
+			slog.Info("project marked for deletion", "project", project.PathWithNamespace, "markedAt", markedAt.Format(time.DateOnly))
+			fmt.Printf("Project %s is marked for deletion, use \"project restore\" to keep it\n", project.PathWithNamespace)
+			return nil
+		}
+		slog.Info("project deleted", "project", project.PathWithNamespace)
+		return nil
+	})
+}
+
+func restoreProjects(cmd *cobra.Command, args []string) error {
+	client, err := clientcmd.NewClient(cmd)
+	if err != nil {
+		return err
+	}
+	if !lifecycleDryRun {
+		if err := clientcmd.Preflight(cmd, client, gitlab.Requirement{Scopes: clientcmd.RequiredScopes(cmd)}); err != nil {
+			return err
+		}
+	}
+
+	projects, err := selectProjects(client, args)
+	if err != nil {
+		return err
+	}
+	return restoreSelected(client, projects)
+}
+
+func restoreSelected(glClient *client.Client, projects []*client.Project) error {
+	skip := func(project *client.Project) string {
+		if project.MarkedForDeletionAt == nil {
+			return "not marked for deletion"
+		}
+		return ""
+	}
+	return runProjects(projects, "restored", lifecycleDryRun, skip, func(project *client.Project) error {
+		restored, err := gitlab.RestoreProject(glClient, project.PathWithNamespace)
+		if err != nil {
+			return err
+		}
+		// Obnoveny projekt muze mit puvodni cestu zpet
+		slog.Info("project restored", "project", restored.PathWithNamespace)
+		return nil
+	})
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+
+	clientcmd "github.com/Cloud-for-You/devops-cli/cmd/gitlab/clientcmd"
+	gitlab "github.com/Cloud-for-You/devops-cli/pkg/gitlab"
+	client "gitlab.com/gitlab-org/api/client-go"
+)
+
+var (
+	forkNamespace string
+	forkName      string
+	forkPath      string
+)
+
+// Fork GitLab projects
+var ForkCmd = &cobra.Command{
+	Use:   "fork [project...]",
+	Short: "Fork GitLab projects",
+	Long: `The "fork" command forks the projects into the --namespace group or user namespace,
+without it into the personal namespace. --name and --path can be used only with a
+single project.
+` + selectHelp,
+	Example: `  devops-cli gitlab-ce project fork platform/billing --namespace sandbox --path billing-experiment
+  devops-cli gitlab-ce project fork --group platform/templates --namespace training`,
+	RunE:        forkProjects,
+	Annotations: map[string]string{clientcmd.ScopesAnnotation: "api"},
+}
+
+func init() {
+	ForkCmd.Flags().StringVar(&forkNamespace, "namespace", "", "full path of the group, username or ID of the target namespace, personal namespace when empty")
+	ForkCmd.Flags().StringVar(&forkName, "name", "", "name of the fork")
+	ForkCmd.Flags().StringVar(&forkPath, "path", "", "path of the fork")
+	ForkCmd.Flags().BoolVar(&lifecycleDryRun, "dryRun", false, "show projects which would be forked without forking them")
+	addSelectFlags(ForkCmd)
+}
+
+func forkProjects(cmd *cobra.Command, args []string) error {
+	client, err := clientcmd.NewClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	// Bez --namespace vznikne fork v osobnim namespace
+	namespace := ""
+	if forkNamespace != "" {
+		ns, err := gitlab.GetNamespace(client, forkNamespace)
+		if err != nil {
+			if gitlab.IsNotFound(err) {
+				return fmt.Errorf("namespace '%s' does not exist or is not visible to the token, use the full path of a group or a username", forkNamespace)
+			}
+			return err
+		}
+		namespace = ns.FullPath
+	}
+	if !lifecycleDryRun {
+		if err := clientcmd.Preflight(cmd, client, gitlab.Requirement{
+			Scopes:          clientcmd.RequiredScopes(cmd),
+			CreateProjectIn: []string{namespace},
+		}); err != nil {
+			return err
+		}
+	}
+
+	projects, err := selectProjects(client, args)
+	if err != nil {
+		return err
+	}
+	if len(projects) > 1 && (forkName != "" || forkPath != "") {
+		return fmt.Errorf("--name and --path can be used only with a single project, %d projects are selected", len(projects))
+	}
+	return forkSelected(client, projects, namespace)
+}
+
+func forkSelected(glClient *client.Client, projects []*client.Project, namespace string) error {
+	action := "forked"
+	if namespace != "" {
+		action = "forked to " + namespace
+	}
+	return runProjects(projects, action, lifecycleDryRun, nil, func(project *client.Project) error {
+		options := &client.ForkProjectOptions{}
+		if namespace != "" {
+			options.NamespacePath = client.Ptr(namespace)
+		}
+		if forkName != "" {
+			options.Name = client.Ptr(forkName)
+		}
+		if forkPath != "" {
+			options.Path = client.Ptr(forkPath)
+		}
+
+		fork, err := gitlab.ForkProject(glClient, project.PathWithNamespace, options)
+		if err != nil {
+			return err
+		}
+		slog.Info("project forked", "project", project.PathWithNamespace, "fork", fork.PathWithNamespace)
+		fmt.Printf("%s -> %s (%s)\n", project.PathWithNamespace, fork.PathWithNamespace, fork.WebURL)
+		return nil
+	})
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	clientcmd "github.com/Cloud-for-You/devops-cli/cmd/gitlab/clientcmd"
+	gitlab "github.com/Cloud-for-You/devops-cli/pkg/gitlab"
+	client "gitlab.com/gitlab-org/api/client-go"
+)
+
+var (
+	selectGroup    string
+	selectFilename string
+)
+
+const selectHelp = `
+Projects are given by full path or ID as arguments, --group selects all projects of
+the group including subgroups (not projects of other namespaces only shared into
+the group) and --filename reads one project per line ("-" reads
+standard input, lines starting with '#' are skipped). All selectors can be combined.`
+
+// addSelectFlags registers flags of commands working on many projects
+func addSelectFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&selectGroup, "group", "", "select all projects of the group including subgroups")
+	cmd.Flags().StringVarP(&selectFilename, "filename", "f", "", "file with one project per line, - reads standard input")
+}
+
+// selectProjects returns projects given as arguments, by --group and by --filename,
+// every project once
+func selectProjects(glClient *client.Client, args []string) ([]*client.Project, error) {
+	if len(args) == 0 && selectGroup == "" && selectFilename == "" {
+		return nil, fmt.Errorf("no projects selected, pass projects as arguments or use --group or --filename")
+	}
+
+	names := args
+	if selectFilename != "" {
+		fromFile, err := readProjectList(selectFilename)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, fromFile...)
+	}
+
+	var projects []*client.Project
+	seen := map[int]bool{}
+	add := func(project *client.Project) {
+		if !seen[project.ID] {
+			seen[project.ID] = true
+			projects = append(projects, project)
+		}
+	}
+
+	for _, name := range names {
+		project, err := gitlab.GetProject(glClient, name)
+		if err != nil {
+			if gitlab.IsNotFound(err) {
+				return nil, fmt.Errorf("project '%s' does not exist or is not visible to the token", name)
+			}
+			return nil, fmt.Errorf("failed to get GitLab project '%s': %w", name, err)
+		}
+		add(project)
+	}
+
+	if selectGroup != "" {
+		groupProjects, err := gitlab.ListGroupProjects(glClient, selectGroup, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, project := range groupProjects {
+			add(project)
+		}
+	}
+
+	if len(projects) == 0 {
+		return nil, fmt.Errorf("no projects found")
+	}
+	return projects, nil
+}
+
+func readProjectList(filename string) ([]string, error) {
+	var input io.Reader = os.Stdin
+	if filename != "-" {
+		file, err := os.Open(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open project list: %w", err)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	var names []string
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		names = append(names, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read project list: %w", err)
+	}
+	return names, nil
+}
+
+// projectPaths returns full paths of the projects
+func projectPaths(projects []*client.Project) []string {
+	paths := make([]string, 0, len(projects))
+	for _, project := range projects {
+		paths = append(paths, project.PathWithNamespace)
+	}
+	return paths
+}
+
+// confirmProjects asks before a destructive action on the projects, a single project
+// is confirmed by its full path, more projects by their count
+func confirmProjects(cmd *cobra.Command, action string, projects []*client.Project) error {
+	if len(projects) == 1 {
+		prompt := fmt.Sprintf("Project %s will be %s.", projects[0].PathWithNamespace, action)
+		return clientcmd.Confirm(cmd, prompt, projects[0].PathWithNamespace)
+	}
+
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "%d projects will be %s:\n", len(projects), action)
+	for _, project := range projects {
+		fmt.Fprintf(&prompt, "  %s\n", project.PathWithNamespace)
+	}
+	return clientcmd.Confirm(cmd, strings.TrimSuffix(prompt.String(), "\n"), strconv.Itoa(len(projects)))
+}
+
+// runProjects calls apply for every project which skip does not leave unchanged,
+// continues after errors and prints a summary. With dryRun the projects which
+// would change are only listed.
+func runProjects(projects []*client.Project, action string, dryRun bool,
+	skip func(project *client.Project) string, apply func(project *client.Project) error) error {
+	var changed, unchanged, failed int
+	for _, project := range projects {
+		if skip != nil {
+			if reason := skip(project); reason != "" {
+				slog.Info("project skipped", "project", project.PathWithNamespace, "reason", reason)
+				unchanged++
+				continue
+			}
+		}
+		if dryRun {
+			fmt.Printf("%s would be %s\n", project.PathWithNamespace, action)
+			changed++
+			continue
+		}
+		if err := apply(project); err != nil {
+			slog.Error("project was not "+action, "project", project.PathWithNamespace, "error", err)
+			failed++
+			continue
+		}
+		changed++
+	}
+
+	if dryRun {
+		fmt.Printf("%d projects would be %s, %d unchanged\n", changed, action, unchanged)
+		return nil
+	}
+	fmt.Printf("%d projects %s, %d unchanged, %d failed\n", changed, action, unchanged, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d projects were not %s", failed, len(projects), action)
+	}
+	return nil
+}
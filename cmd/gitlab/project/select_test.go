@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	client "gitlab.com/gitlab-org/api/client-go"
+)
+
+// captureOutput returns what f prints to standard output
+func captureOutput(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	f()
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+// setSelect sets the selector flags for one test
+func setSelect(t *testing.T, group, filename string) {
+	t.Helper()
+	selectGroup, selectFilename = group, filename
+	t.Cleanup(func() { selectGroup, selectFilename = "", "" })
+}
+
+func writeProjectList(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "projects.txt")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadProjectList(t *testing.T) {
+	path := writeProjectList(t, "# retired projects\nplatform/legacy\n\n  platform/billing  \n\t# 42\n42\n")
+
+	names, err := readProjectList(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"platform/legacy", "platform/billing", "42"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("got %q, want %q", names, want)
+	}
+
+	if _, err := readProjectList(path + ".missing"); err == nil || !strings.Contains(err.Error(), "failed to open project list") {
+		t.Errorf("missing file: error %v", err)
+	}
+}
+
+func TestSelectProjects(t *testing.T) {
+	responses := map[string]string{
+		"/api/v4/projects/platform%2Flegacy": `{"id": 5, "path_with_namespace": "platform/legacy"}`,
+		"/api/v4/projects/5":                 `{"id": 5, "path_with_namespace": "platform/legacy"}`,
+		"/api/v4/groups/platform/projects": `[
+			{"id": 6, "path_with_namespace": "platform/billing", "archived": true},
+			{"id": 5, "path_with_namespace": "platform/legacy"}
+		]`,
+	}
+	var groupQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.EscapedPath() == "/api/v4/groups/platform/projects" {
+			groupQuery = r.URL.RawQuery
+		}
+		body, ok := responses[r.URL.EscapedPath()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			body = `{"message": "404 Not found"}`
+		}
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	glClient, err := client.NewClient("token", client.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("every project once", func(t *testing.T) {
+		setSelect(t, "platform", writeProjectList(t, "5\n"))
+		projects, err := selectProjects(glClient, []string{"platform/legacy"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{"platform/legacy", "platform/billing"}
+		if got := projectPaths(projects); !reflect.DeepEqual(got, want) {
+			t.Errorf("got %q, want %q", got, want)
+		}
+		// Archivovane projekty skupiny se nefiltruji, preskoci je az prikaz
+		if strings.Contains(groupQuery, "archived") {
+			t.Errorf("group projects filtered by archived state: %s", groupQuery)
+		}
+	})
+
+	t.Run("missing project", func(t *testing.T) {
+		setSelect(t, "", "")
+		_, err := selectProjects(glClient, []string{"platform/missing"})
+		if err == nil || !strings.Contains(err.Error(), "project 'platform/missing' does not exist") {
+			t.Errorf("error %v", err)
+		}
+	})
+
+	t.Run("nothing selected", func(t *testing.T) {
+		setSelect(t, "", "")
+		_, err := selectProjects(glClient, nil)
+		if err == nil || !strings.Contains(err.Error(), "no projects selected") {
+			t.Errorf("error %v", err)
+		}
+	})
+}
+
+func TestRunProjects(t *testing.T) {
+	projects := []*client.Project{
+		{ID: 1, PathWithNamespace: "platform/api"},
+		{ID: 2, PathWithNamespace: "platform/legacy"},
+		{ID: 3, PathWithNamespace: "platform/billing"},
+		{ID: 4, PathWithNamespace: "platform/web"},
+	}
+	skip := func(project *client.Project) string {
+		if project.ID == 2 {
+			return "already archived"
+		}
+		return ""
+	}
+
+	tests := []struct {
+		name        string
+		dryRun      bool
+		wantApplied []int
+		wantOutput  string
+		wantErr     string
+	}{
+		{
+			name:        "apply",
+			wantApplied: []int{1, 3, 4},
+			wantOutput:  "2 projects archived, 1 unchanged, 1 failed\n",
+			wantErr:     "1 of 4 projects were not archived",
+		},
+		{
+			name:       "dry run",
+			dryRun:     true,
+			wantOutput: "platform/api would be archived\nplatform/billing would be archived\nplatform/web would be archived\n3 projects would be archived, 1 unchanged\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var applied []int
+			var err error
+			out := captureOutput(t, func() {
+				err = runProjects(projects, "archived", tt.dryRun, skip, func(project *client.Project) error {
+					applied = append(applied, project.ID)
+					if project.ID == 3 {
+						return errors.New("forbidden")
+					}
+					return nil
+				})
+			})
+
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("error %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Error(err)
+			}
+			if !reflect.DeepEqual(applied, tt.wantApplied) {
+				t.Errorf("applied %v, want %v", applied, tt.wantApplied)
+			}
+			if out != tt.wantOutput {
+				t.Errorf("output %q, want %q", out, tt.wantOutput)
+			}
+		})
+	}
+}
+
+func TestDeleteSelectedSkip(t *testing.T) {
+	markedAt := client.ISOTime(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC))
+	projects := []*client.Project{
+		{ID: 1, PathWithNamespace: "platform/api"},
+		{ID: 2, PathWithNamespace: "platform/legacy-deletion_scheduled-2", MarkedForDeletionAt: &markedAt},
+	}
+
+	tests := []struct {
+		permanentlyRemove bool
+		want              string
+	}{
+		{want: "platform/api would be deleted\n1 projects would be deleted, 1 unchanged\n"},
+		{permanentlyRemove: true, want: "platform/legacy-deletion_scheduled-2 would be deleted\n1 projects would be deleted, 1 unchanged\n"},
+	}
+
+	lifecycleDryRun = true
+	defer func() { lifecycleDryRun, permanentlyRemove = false, false }()
+	for _, tt := range tests {
+		permanentlyRemove = tt.permanentlyRemove
+		// Dry run nevola API, klient neni potreba
+		out := captureOutput(t, func() {
+			if err := deleteSelected(nil, projects); err != nil {
+				t.Error(err)
+			}
+		})
+		if out != tt.want {
+			t.Errorf("permanentlyRemove %t: output %q, want %q", tt.permanentlyRemove, out, tt.want)
+		}
+	}
+}
+
+func TestChangeArchivedRerun(t *testing.T) {
+	projects := []*client.Project{
+		{ID: 1, PathWithNamespace: "platform/api", Archived: true},
+		{ID: 2, PathWithNamespace: "platform/legacy", Archived: true},
+	}
+
+	// Opakovane archivovani nic nemeni a neskonci chybou
+	out := captureOutput(t, func() {
+		if err := changeArchived(nil, projects, true); err != nil {
+			t.Error(err)
+		}
+	})
+	if want := "0 projects archived, 2 unchanged, 0 failed\n"; out != want {
+		t.Errorf("output %q, want %q", out, want)
+	}
+}
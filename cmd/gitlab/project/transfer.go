@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+
+	clientcmd "github.com/Cloud-for-You/devops-cli/cmd/gitlab/clientcmd"
+	gitlab "github.com/Cloud-for-You/devops-cli/pkg/gitlab"
+	client "gitlab.com/gitlab-org/api/client-go"
+)
+
+var transferNamespace string
+
+// Transfer GitLab projects into another namespace
+var TransferCmd = &cobra.Command{
+	Use:   "transfer [project...]",
+	Short: "Move GitLab projects into another namespace",
+	Long: `The "transfer" command moves the projects into the --namespace group or user
+namespace, projects already in it are skipped. The full path of the projects changes,
+GitLab redirects the old paths until they are taken by another project. The command
+asks to type the full path of the project, or the number of projects when more of
+them are selected, --yes skips the question.
+` + selectHelp,
+	Example: `  devops-cli gitlab-ce project transfer platform/billing --namespace finance
+  devops-cli gitlab-ce project transfer --group platform/backend --namespace engineering/backend --dryRun
+  devops-cli gitlab-ce project transfer -f reorg.txt --namespace engineering --yes`,
+	RunE:        transferProjects,
+	Annotations: map[string]string{clientcmd.ScopesAnnotation: "api"},
+}
+
+func init() {
+	TransferCmd.Flags().StringVar(&transferNamespace, "namespace", "", "full path of the group, username or ID of the target namespace (required)")
+	TransferCmd.Flags().BoolVar(&lifecycleDryRun, "dryRun", false, "show projects which would change without changing them")
+	TransferCmd.MarkFlagRequired("namespace")
+	clientcmd.AddConfirmFlag(TransferCmd.Flags())
+	addSelectFlags(TransferCmd)
+}
+
+func transferProjects(cmd *cobra.Command, args []string) error {
+	client, err := clientcmd.NewClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	namespace, err := gitlab.GetNamespace(client, transferNamespace)
+	if err != nil {
+		if gitlab.IsNotFound(err) {
+			return fmt.Errorf("namespace '%s' does not exist or is not visible to the token, use the full path of a group or a username", transferNamespace)
+		}
+		return err
+	}
+
+	projects, err := selectProjects(client, args)
+	if err != nil {
+		return err
+	}
+	if !lifecycleDryRun {
+		// Projekt muze presunout jen vlastnik
+		if err := clientcmd.Preflight(cmd, client, gitlab.Requirement{
+			Scopes:          clientcmd.RequiredScopes(cmd),
+			ProjectOwnerOf:  projectPaths(projects),
+			CreateProjectIn: []string{namespace.FullPath},
+		}); err != nil {
+			return err
+		}
+		if err := confirmProjects(cmd, "transferred to "+namespace.FullPath, projects); err != nil {
+			return err
+		}
+	}
+	return transferSelected(client, projects, namespace)
+}
+
+func transferSelected(glClient *client.Client, projects []*client.Project, namespace *client.Namespace) error {
+	skip := func(project *client.Project) string {
+		if project.Namespace != nil && project.Namespace.ID == namespace.ID {
+			return "already in " + namespace.FullPath
+		}
+		return ""
+	}
+	return runProjects(projects, "transferred to "+namespace.FullPath, lifecycleDryRun, skip, func(project *client.Project) error {
+		transferred, err := gitlab.TransferProject(glClient, project.PathWithNamespace, namespace.FullPath)
+		if err != nil {
+			return err
+		}
+		slog.Info("project transferred", "project", project.PathWithNamespace, "fullPath", transferred.PathWithNamespace)
+		fmt.Printf("%s -> %s\n", project.PathWithNamespace, transferred.PathWithNamespace)
+		return nil
+	})
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	clientcmd "github.com/Cloud-for-You/devops-cli/cmd/gitlab/clientcmd"
+	gitlab "github.com/Cloud-for-You/devops-cli/pkg/gitlab"
+	client "gitlab.com/gitlab-org/api/client-go"
+)
+
+var (
+	settingsProfile string
+	updateDryRun    bool
+)
+
+// Update settings of GitLab projects
+var UpdateCmd = &cobra.Command{
+	Use:   "update [project...]",
+	Short: "Update settings of GitLab projects",
+	Long: `The "update" command changes only the settings given on the command line or in
+the --settingsProfile, projects which already have them are left unchanged.
+--dryRun shows the changes without applying them.
+` + selectHelp + `
+
+Settings profiles are defined in the configuration file, flags given on the command
+line override the profile:
+
+  projectSettings:
+    governance:
+      mergeMethod: ff
+      squashOption: default_on
+      onlyAllowMergeIfPipelineSucceeds: true
+      onlyAllowMergeIfAllDiscussionsAreResolved: true
+      removeSourceBranchAfterMerge: true
+      wikiEnabled: false
+      snippetsEnabled: false`,
+	Example: `  devops-cli gitlab-ce project update platform/billing --mergeMethod ff --squashOption always
+  devops-cli gitlab-ce project update --group platform --settingsProfile governance --dryRun
+  devops-cli gitlab-ce project update -f projects.txt --ciConfigPath ci/pipeline.yml`,
+	RunE:        updateProjects,
+	Annotations: map[string]string{clientcmd.ScopesAnnotation: "api"},
+}
+
+func init() {
+	UpdateCmd.Flags().String("defaultBranch", "", "name of the default branch, the branch must exist")
+	UpdateCmd.Flags().String("mergeMethod", "", "merge method: merge, rebase_merge or ff")
+	UpdateCmd.Flags().String("squashOption", "", "squash commits when merging: never, always, default_on or default_off")
+	UpdateCmd.Flags().Bool("onlyAllowMergeIfPipelineSucceeds", false, "pipelines must succeed before merging")
+	UpdateCmd.Flags().Bool("onlyAllowMergeIfAllDiscussionsAreResolved", false, "all discussions must be resolved before merging")
+	UpdateCmd.Flags().Bool("removeSourceBranchAfterMerge", false, "delete the source branch after merging by default")
+	UpdateCmd.Flags().String("ciConfigPath", "", "path of the CI configuration, empty string is .gitlab-ci.yml")
+	UpdateCmd.Flags().Bool("issuesEnabled", false, "enable issues")
+	UpdateCmd.Flags().Bool("wikiEnabled", false, "enable wiki")
+	UpdateCmd.Flags().Bool("snippetsEnabled", false, "enable snippets")
+	UpdateCmd.Flags().Bool("containerRegistryEnabled", false, "enable container registry")
+	UpdateCmd.Flags().Bool("packagesEnabled", false, "enable package registry")
+	UpdateCmd.Flags().StringVar(&settingsProfile, "settingsProfile", "", "apply settings of the profile from projectSettings in the configuration file")
+	UpdateCmd.Flags().BoolVar(&updateDryRun, "dryRun", false, "show changes without applying them")
+	addSelectFlags(UpdateCmd)
+}
+
+func updateProjects(cmd *cobra.Command, args []string) error {
+	settings, err := updateSettings(cmd)
+	if err != nil {
+		return err
+	}
+
+	client, err := clientcmd.NewClient(cmd)
+	if err != nil {
+		return err
+	}
+	projects, err := selectProjects(client, args)
+	if err != nil {
+		return err
+	}
+
+	// Nastaveni projektu meni maintainer, overujeme jen projekty se zmenami
+	changesOf := map[string][]string{}
+	var changed []string
+	for _, project := range projects {
+		if changes := settings.Changes(project); len(changes) > 0 {
+			changesOf[project.PathWithNamespace] = changes
+			changed = append(changed, project.PathWithNamespace)
+		}
+	}
+	if !updateDryRun && len(changed) > 0 {
+		if err := clientcmd.Preflight(cmd, client, gitlab.Requirement{
+			Scopes:       clientcmd.RequiredScopes(cmd),
+			MaintainerOf: changed,
+		}); err != nil {
+			return err
+		}
+	}
+
+	var updated, unchanged, failed int
+	for _, project := range projects {
+		changes := changesOf[project.PathWithNamespace]
+		if len(changes) == 0 {
+			unchanged++
+			continue
+		}
+
+		fmt.Printf("%s\n", project.PathWithNamespace)
+		for _, change := range changes {
+			fmt.Printf("  ~ %s\n", change)
+		}
+		if updateDryRun {
+			updated++
+			continue
+		}
+
+		if err := updateProject(client, project, settings); err != nil {
+			slog.Error("project was not updated", "project", project.PathWithNamespace, "error", err)
+			failed++
+			continue
+		}
+		slog.Info("project updated", "project", project.PathWithNamespace, "changes", len(changes))
+		updated++
+	}
+
+	if updateDryRun {
+		fmt.Printf("%d projects would be updated, %d unchanged\n", updated, unchanged)
+		return nil
+	}
+	fmt.Printf("%d projects updated, %d unchanged, %d failed\n", updated, unchanged, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d projects were not updated", failed, len(projects))
+	}
+	return nil
+}
+
+func updateProject(glClient *client.Client, project *client.Project, settings gitlab.ProjectSettings) error {
+	options := &client.EditProjectOptions{}
+	settings.EditOptions(options)
+	_, err := gitlab.UpdateProject(glClient, project.PathWithNamespace, options)
+	return err
+}
+
+// updateSettings returns settings of --settingsProfile overridden by flags given on the command line
+func updateSettings(cmd *cobra.Command) (gitlab.ProjectSettings, error) {
+	var settings gitlab.ProjectSettings
+	if settingsProfile != "" {
+		key := "projectSettings." + settingsProfile
+		if !viper.IsSet(key) {
+			return settings, fmt.Errorf("settings profile %q is not defined in projectSettings of the configuration file", settingsProfile)
+		}
+		if err := viper.UnmarshalKey(key, &settings); err != nil {
+			return settings, fmt.Errorf("invalid settings profile %q: %w", settingsProfile, err)
+		}
+	}
+
+	flags := cmd.Flags()
+	var fromFlags gitlab.ProjectSettings
+	stringFlag := func(name string, value **string) {
+		if flags.Changed(name) {
+			v, _ := flags.GetString(name)
+			*value = &v
+		}
+	}
+	boolFlag := func(name string, value **bool) {
+		if flags.Changed(name) {
+			v, _ := flags.GetBool(name)
+			*value = &v
+		}
+	}
+	stringFlag("defaultBranch", &fromFlags.DefaultBranch)
+	stringFlag("mergeMethod", &fromFlags.MergeMethod)
+	stringFlag("squashOption", &fromFlags.SquashOption)
+	boolFlag("onlyAllowMergeIfPipelineSucceeds", &fromFlags.OnlyAllowMergeIfPipelineSucceeds)
+	boolFlag("onlyAllowMergeIfAllDiscussionsAreResolved", &fromFlags.OnlyAllowMergeIfAllDiscussionsAreResolved)
+	boolFlag("removeSourceBranchAfterMerge", &fromFlags.RemoveSourceBranchAfterMerge)
+	stringFlag("ciConfigPath", &fromFlags.CIConfigPath)
+	boolFlag("issuesEnabled", &fromFlags.IssuesEnabled)
+	boolFlag("wikiEnabled", &fromFlags.WikiEnabled)
+	boolFlag("snippetsEnabled", &fromFlags.SnippetsEnabled)
+	boolFlag("containerRegistryEnabled", &fromFlags.ContainerRegistryEnabled)
+	boolFlag("packagesEnabled", &fromFlags.PackagesEnabled)
+
+	settings = settings.Merge(fromFlags)
+	if settings.Empty() {
+		return settings, fmt.Errorf("nothing to update, set at least one setting flag or --settingsProfile")
+	}
+	return settings, settings.Validate()
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	clientcmd "github.com/Cloud-for-You/devops-cli/cmd/gitlab/clientcmd"
+	gitlab "github.com/Cloud-for-You/devops-cli/pkg/gitlab"
+)
+
+var (
+	whoamiOutput     string
+	whoamiCheck      []string
+	expiryWarnPeriod int
+)
+
+// Display the current user, GitLab instance and token details
+var WhoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Display the current user, GitLab instance and token details",
+	Long: `The "whoami" command displays the authenticated user, version of the GitLab
+instance and name, scopes, expiry and last use of the access token.
+
+A warning is logged when the token expires within --expiryWarnDays days and when
+it lacks scopes needed by the commands given with --check.`,
+	Example: `  devops-cli gitlab-ce whoami --profile prod
+  devops-cli gitlab-ce whoami --check "groupsync ldap" --check "project create"
+  devops-cli gitlab-ce whoami -o json | jq -r .token.expiresAt`,
+	Args:        cobra.NoArgs,
+	RunE:        whoami,
+	Annotations: map[string]string{clientcmd.ScopesAnnotation: "read_user"},
+}
+
+func init() {
+	WhoamiCmd.Flags().StringVarP(&whoamiOutput, "output", "o", "text", "output format: text or json")
+	WhoamiCmd.Flags().StringArrayVar(&whoamiCheck, "check", nil, "warn when the token lacks scopes needed by this gitlab-ce command, e.g. \"group create\"")
+	WhoamiCmd.Flags().IntVar(&expiryWarnPeriod, "expiryWarnDays", 14, "warn when the token expires within this number of days")
+}
+
+// identityOutput is the JSON output of whoami
+type identityOutput struct {
+	*gitlab.Identity
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+func whoami(cmd *cobra.Command, args []string) error {
+	if whoamiOutput != "text" && whoamiOutput != "json" {
+		return fmt.Errorf("unsupported output format %q (supported: text, json)", whoamiOutput)
+	}
+
+	// Prikazy pro kontrolu overime jeste pred volanim API
+	var required []string
+	for _, path := range whoamiCheck {
+		target, rest, err := GitlabCmd.Find(strings.Fields(path))
+		if err != nil || len(rest) > 0 || target == GitlabCmd {
+			return fmt.Errorf("unknown command %q", path)
+		}
+		required = append(required, clientcmd.RequiredScopes(target)...)
+	}
+
+	client, err := clientcmd.NewClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	identity, err := gitlab.GetIdentity(client)
+	if err != nil {
+		return err
+	}
+
+	warnings := identityWarnings(identity, required)
+	for _, warning := range warnings {
+		slog.Warn(warning)
+	}
+
+	if whoamiOutput == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(identityOutput{Identity: identity, Warnings: warnings})
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "User:\t%s (ID %d)\n", identity.Username, identity.UserID)
+	fmt.Fprintf(w, "Name:\t%s\n", identity.Name)
+	fmt.Fprintf(w, "State:\t%s\n", identity.State)
+	fmt.Fprintf(w, "Admin:\t%t\n", identity.IsAdmin)
+	if identity.Bot {
+		fmt.Fprintf(w, "Bot:\t%t\n", identity.Bot)
+	}
+	fmt.Fprintf(w, "Instance:\t%s\n", identity.InstanceURL)
+	if identity.Version != "" {
+		fmt.Fprintf(w, "Version:\t%s (%s)\n", identity.Version, identity.Revision)
+	}
+	if token := identity.Token; token != nil {
+		fmt.Fprintf(w, "Token:\t%s (ID %d)\n", token.Name, token.ID)
+		fmt.Fprintf(w, "Scopes:\t%s\n", strings.Join(token.Scopes, ", "))
+		fmt.Fprintf(w, "Expires:\t%s\n", formatTime(token.ExpiresAt, time.DateOnly))
+		fmt.Fprintf(w, "Last used:\t%s\n", formatTime(token.LastUsedAt, time.RFC3339))
+	} else {
+		fmt.Fprintf(w, "Token:\tdetails not available (OAuth2, job token or GitLab older than 15.5)\n")
+	}
+	return w.Flush()
+}
+
+// identityWarnings checks token expiry and scopes needed by the checked commands
+func identityWarnings(identity *gitlab.Identity, required []string) []string {
+	var warnings []string
+	token := identity.Token
+	if token == nil {
+		if len(required) > 0 {
+			warnings = append(warnings, "token scopes cannot be verified, token details are not available")
+		}
+		return warnings
+	}
+
+	if !token.Active {
+		warnings = append(warnings, fmt.Sprintf("token %q is not active", token.Name))
+	}
+	if token.ExpiresAt != nil {
+		left := time.Until(*token.ExpiresAt)
+		if left < time.Duration(expiryWarnPeriod)*24*time.Hour {
+			warnings = append(warnings, fmt.Sprintf("token %q expires on %s (in %d days)", token.Name, token.ExpiresAt.Format(time.DateOnly), int(left.Hours()/24)))
+		}
+	}
+	if missing := token.MissingScopes(required); len(missing) > 0 {
+		warnings = append(warnings, fmt.Sprintf("token %q lacks scopes %s", token.Name, strings.Join(missing, ", ")))
+	}
+	return warnings
+}
+
+func formatTime(t *time.Time, layout string) string {
+	if t == nil {
+		return "never"
+	}
+	return t.Format(layout)
+}
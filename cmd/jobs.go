@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// JobFile is a list of commands executed by "devops-cli run".
+// The older format with top-level command and flags is read as a single job.
+type JobFile struct {
+	Jobs []Job `yaml:"jobs"`
+	// ContinueOnError runs remaining jobs after a failed one
+	ContinueOnError bool `yaml:"continueOnError"`
+
+	// Puvodni format konfigurace s jedinym prikazem
+	Command string         `yaml:"command"`
+	Flags   map[string]any `yaml:"flags"`
+}
+
+// Job is one command with its flags. Flag values may be strings, numbers,
+// booleans or lists, secret flags accept references (env:, file:, vault:).
+type Job struct {
+	Name    string         `yaml:"name"`
+	Command string         `yaml:"command"`
+	Flags   map[string]any `yaml:"flags"`
+}
+
+// preparedJob is a validated job with the command found and arguments built
+type preparedJob struct {
+	Job
+	target *cobra.Command
+	args   []string
+}
+
+// loadJobFile reads the job file. YAML is used directly instead of viper,
+// because viper lowercases keys and flag names are case sensitive.
+func loadJobFile(path string) (*JobFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading job file: %w", err)
+	}
+
+	var file JobFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("error parsing job file %s: %w", path, err)
+	}
+
+	if file.Command != "" {
+		if len(file.Jobs) > 0 {
+			return nil, fmt.Errorf("job file %s contains both command and jobs", path)
+		}
+		file.Jobs = []Job{{Name: "default", Command: file.Command, Flags: file.Flags}}
+	}
+	if len(file.Jobs) == 0 {
+		return nil, fmt.Errorf("job file %s contains no jobs", path)
+	}
+	return &file, nil
+}
+
+// prepareJobs validates the selected jobs (all when names is empty) before any of them runs
+func prepareJobs(root *cobra.Command, file *JobFile, names []string) ([]preparedJob, error) {
+	byName := map[string]Job{}
+	for i, job := range file.Jobs {
+		if job.Name == "" {
+			job.Name = fmt.Sprintf("job-%d", i+1)
+			file.Jobs[i] = job
+		}
+		if _, exists := byName[job.Name]; exists {
+			return nil, fmt.Errorf("duplicate job name %q", job.Name)
+		}
+		byName[job.Name] = job
+	}
+
+	selected := file.Jobs
+	if len(names) > 0 {
+		selected = nil
+		for _, name := range names {
+			job, ok := byName[name]
+			if !ok {
+				return nil, fmt.Errorf("job %q not found", name)
+			}
+			selected = append(selected, job)
+		}
+	}
+
+	var prepared []preparedJob
+	var errs []error
+	for _, job := range selected {
+		p, err := prepareJob(root, job)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("job %q: %w", job.Name, err))
+			continue
+		}
+		prepared = append(prepared, p)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return prepared, nil
+}
+
+func prepareJob(root *cobra.Command, job Job) (preparedJob, error) {
+	// Cesta prikazu muze byt oddelena mezerami nebo lomitky (puvodni format)
+	path := strings.FieldsFunc(job.Command, func(r rune) bool { return r == '/' || r == ' ' })
+	if len(path) == 0 {
+		return preparedJob{}, fmt.Errorf("command is empty")
+	}
+	target, rest, err := root.Find(path)
+	if err != nil || len(rest) > 0 || target == root {
+		return preparedJob{}, fmt.Errorf("unknown command %q", job.Command)
+	}
+	if !target.Runnable() || target.Parent() == root && target.Name() == "run" {
+		return preparedJob{}, fmt.Errorf("command %q cannot be run from a job file", job.Command)
+	}
+
+	args := append([]string{}, path...)
+	names := make([]string, 0, len(job.Flags))
+	for name := range job.Flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		flag := lookupFlag(target, name)
+		if flag == nil {
+			return preparedJob{}, fmt.Errorf("unknown flag --%s of command %q", name, job.Command)
+		}
+		values, err := flagValues(flag, job.Flags[name])
+		if err != nil {
+			return preparedJob{}, fmt.Errorf("flag --%s: %w", name, err)
+		}
+		for _, value := range values {
+			args = append(args, fmt.Sprintf("--%s=%s", name, value))
+		}
+	}
+
+	// Povinne flagy overime predem, jinak by je cobra odhalila az pri behu ulohy
+	var missing []string
+	target.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		if _, required := flag.Annotations[cobra.BashCompOneRequiredFlag]; required {
+			if _, set := job.Flags[flag.Name]; !set {
+				missing = append(missing, "--"+flag.Name)
+			}
+		}
+	})
+	if len(missing) > 0 {
+		return preparedJob{}, fmt.Errorf("required flags not set: %s", strings.Join(missing, ", "))
+	}
+
+	return preparedJob{Job: job, target: target, args: args}, nil
+}
+
+// lookupFlag finds a local or inherited flag of the command
+func lookupFlag(cmd *cobra.Command, name string) *pflag.Flag {
+	for c := cmd; c != nil; c = c.Parent() {
+		if flag := c.Flags().Lookup(name); flag != nil {
+			return flag
+		}
+		if flag := c.PersistentFlags().Lookup(name); flag != nil {
+			return flag
+		}
+	}
+	return nil
+}
+
+// flagValues converts a value from the job file to command line values checked against the flag type
+func flagValues(flag *pflag.Flag, value any) ([]string, error) {
+	list, isList := value.([]any)
+	slice := strings.HasSuffix(flag.Value.Type(), "Slice") || strings.HasSuffix(flag.Value.Type(), "Array")
+	if isList && !slice {
+		return nil, fmt.Errorf("flag of type %s does not accept a list", flag.Value.Type())
+	}
+	if !isList {
+		list = []any{value}
+	}
+
+	var values []string
+	for _, item := range list {
+		switch item.(type) {
+		case map[string]any, []any, nil:
+			return nil, fmt.Errorf("unsupported value %v", item)
+		}
+		s := fmt.Sprint(item)
+		if err := checkFlagValue(flag.Value.Type(), s); err != nil {
+			return nil, err
+		}
+		values = append(values, s)
+	}
+	return values, nil
+}
+
+func checkFlagValue(flagType, value string) error {
+	var err error
+	switch flagType {
+	case "bool":
+		_, err = strconv.ParseBool(value)
+	case "int", "intSlice":
+		_, err = strconv.Atoi(value)
+	case "duration":
+		_, err = time.ParseDuration(value)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s value %q", flagType, value)
+	}
+	return nil
+}
+
+// flagState is the value of a flag given to "devops-cli run" itself
+type flagState struct {
+	value   string
+	changed bool
+}
+
+// resetFlags sets flags of the command and its parents back to defaults,
+// so values of a previous job do not leak into the next one. Flags of the
+// root command keep the values given to "devops-cli run".
+func resetFlags(cmd *cobra.Command, rootFlags map[string]flagState) {
+	reset := func(flag *pflag.Flag) {
+		if state, ok := rootFlags[flag.Name]; ok {
+			flag.Value.Set(state.value)
+			flag.Changed = state.changed
+			return
+		}
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			slice.Replace(nil)
+		} else {
+			flag.Value.Set(flag.DefValue)
+		}
+		flag.Changed = false
+	}
+	for c := cmd; c != nil; c = c.Parent() {
+		c.Flags().VisitAll(reset)
+		c.PersistentFlags().VisitAll(reset)
+	}
+}
+
+// runJobs executes prepared jobs one by one
+func runJobs(root *cobra.Command, jobs []preparedJob, continueOnError bool) error {
+	rootFlags := map[string]flagState{}
+	root.PersistentFlags().VisitAll(func(flag *pflag.Flag) {
+		rootFlags[flag.Name] = flagState{value: flag.Value.String(), changed: flag.Changed}
+	})
+
+	var failed []string
+	for _, job := range jobs {
+		resetFlags(job.target, rootFlags)
+		root.SetArgs(job.args)
+
+		start := time.Now()
+		slog.Info("job started", "job", job.Name, "command", job.target.CommandPath())
+		if err := root.Execute(); err != nil {
+			slog.Error("job failed", "job", job.Name, "duration", time.Since(start), "error", err)
+			failed = append(failed, job.Name)
+			if !continueOnError {
+				break
+			}
+			continue
+		}
+		slog.Info("job finished", "job", job.Name, "duration", time.Since(start))
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed jobs: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
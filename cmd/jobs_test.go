@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func writeJobFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "jobs.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadJobFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []Job
+		wantErr string
+	}{
+		{
+			name: "jobs",
+			content: `
+continueOnError: true
+jobs:
+  - name: sync
+    command: gitlab-ce groupsync ldap
+    flags:
+      ldapGroupDN:
+        - CN=GL-Backend,OU=Groups,DC=example,DC=com
+  - command: gitlab-ce whoami
+`,
+			want: []Job{
+				{Name: "sync", Command: "gitlab-ce groupsync ldap", Flags: map[string]any{"ldapGroupDN": []any{"CN=GL-Backend,OU=Groups,DC=example,DC=com"}}},
+				{Command: "gitlab-ce whoami"},
+			},
+		},
+		{
+			name:    "single command",
+			content: "command: gitlab-ce/whoami\nflags:\n  gitlabRetries: 3\n",
+			want:    []Job{{Name: "default", Command: "gitlab-ce/whoami", Flags: map[string]any{"gitlabRetries": 3}}},
+		},
+		{
+			name:    "flag names keep case",
+			content: "command: gitlab-ce whoami\nflags:\n  gitlabUrl: https://gitlab.example.com\n",
+			want:    []Job{{Name: "default", Command: "gitlab-ce whoami", Flags: map[string]any{"gitlabUrl": "https://gitlab.example.com"}}},
+		},
+		{
+			name:    "command and jobs",
+			content: "command: gitlab-ce whoami\njobs:\n  - command: gitlab-ce whoami\n",
+			wantErr: "contains both command and jobs",
+		},
+		{name: "no jobs", content: "continueOnError: true\n", wantErr: "contains no jobs"},
+		{name: "invalid yaml", content: "jobs: [\n", wantErr: "error parsing job file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := loadJobFile(writeJobFile(t, tt.content))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(file.Jobs, tt.want) {
+				t.Errorf("got %#v, want %#v", file.Jobs, tt.want)
+			}
+		})
+	}
+
+	if _, err := loadJobFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil || !strings.Contains(err.Error(), "error reading job file") {
+		t.Errorf("missing file: error %v", err)
+	}
+}
+
+// newJobTestRoot builds a command tree shaped like the real one
+func newJobTestRoot() *cobra.Command {
+	noop := func(cmd *cobra.Command, args []string) error { return nil }
+
+	root := &cobra.Command{Use: "devops-cli", RunE: noop}
+	root.PersistentFlags().Bool("debug", false, "")
+	root.AddCommand(&cobra.Command{Use: "run", RunE: noop})
+
+	gitlab := &cobra.Command{Use: "gitlab-ce"}
+	gitlab.PersistentFlags().String("gitlabUrl", "", "")
+	group := &cobra.Command{Use: "group"}
+	members := &cobra.Command{Use: "members", RunE: noop}
+	members.Flags().String("group", "", "")
+	members.MarkFlagRequired("group")
+	members.Flags().StringSlice("member", nil, "")
+	members.Flags().Bool("dryRun", false, "")
+	members.Flags().Int("retries", 0, "")
+	group.AddCommand(members)
+	gitlab.AddCommand(group)
+	root.AddCommand(gitlab)
+	return root
+}
+
+func TestPrepareJob(t *testing.T) {
+	tests := []struct {
+		name    string
+		job     Job
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "flags are sorted and lists repeated",
+			job: Job{Command: "gitlab-ce group members", Flags: map[string]any{
+				"group":     "platform",
+				"member":    []any{"alice", "bob"},
+				"dryRun":    true,
+				"gitlabUrl": "https://gitlab.example.com",
+				"debug":     false,
+			}},
+			want: []string{"gitlab-ce", "group", "members", "--debug=false", "--dryRun=true", "--gitlabUrl=https://gitlab.example.com", "--group=platform", "--member=alice", "--member=bob"},
+		},
+		{
+			name: "command path with slashes",
+			job:  Job{Command: "gitlab-ce/group/members", Flags: map[string]any{"group": "platform", "retries": 3}},
+			want: []string{"gitlab-ce", "group", "members", "--group=platform", "--retries=3"},
+		},
+		{name: "empty command", job: Job{Command: " / "}, wantErr: "command is empty"},
+		{name: "unknown command", job: Job{Command: "gitlab-ce project"}, wantErr: `unknown command "gitlab-ce project"`},
+		{name: "not runnable", job: Job{Command: "gitlab-ce group"}, wantErr: "cannot be run from a job file"},
+		{name: "run itself", job: Job{Command: "run"}, wantErr: "cannot be run from a job file"},
+		{
+			name:    "unknown flag",
+			job:     Job{Command: "gitlab-ce group members", Flags: map[string]any{"group": "platform", "groups": "x"}},
+			wantErr: "unknown flag --groups",
+		},
+		{
+			name:    "invalid flag value",
+			job:     Job{Command: "gitlab-ce group members", Flags: map[string]any{"group": "platform", "retries": "many"}},
+			wantErr: "flag --retries: invalid int value",
+		},
+		{
+			name:    "missing required flag",
+			job:     Job{Command: "gitlab-ce group members", Flags: map[string]any{"dryRun": true}},
+			wantErr: "required flags not set: --group",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := newJobTestRoot()
+			prepared, err := prepareJob(root, tt.job)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(prepared.args, tt.want) {
+				t.Errorf("args %v, want %v", prepared.args, tt.want)
+			}
+			if prepared.target.Name() != "members" {
+				t.Errorf("target %s, want members", prepared.target.CommandPath())
+			}
+		})
+	}
+}
+
+func TestFlagValues(t *testing.T) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("name", "", "")
+	flags.Bool("dryRun", false, "")
+	flags.Int("retries", 0, "")
+	flags.Duration("timeout", time.Second, "")
+	flags.StringSlice("member", nil, "")
+	flags.StringArray("groupDN", nil, "")
+	flags.IntSlice("ids", nil, "")
+
+	tests := []struct {
+		flag    string
+		value   any
+		want    []string
+		wantErr string
+	}{
+		{flag: "name", value: "platform", want: []string{"platform"}},
+		{flag: "name", value: 42, want: []string{"42"}},
+		{flag: "dryRun", value: true, want: []string{"true"}},
+		{flag: "dryRun", value: "yes", wantErr: `invalid bool value "yes"`},
+		{flag: "retries", value: 3, want: []string{"3"}},
+		{flag: "retries", value: "3.5", wantErr: `invalid int value "3.5"`},
+		{flag: "timeout", value: "90s", want: []string{"90s"}},
+		{flag: "timeout", value: 90, wantErr: `invalid duration value "90"`},
+		{flag: "member", value: []any{"alice", "bob"}, want: []string{"alice", "bob"}},
+		{flag: "member", value: "alice", want: []string{"alice"}},
+		{flag: "groupDN", value: []any{"CN=A,DC=example,DC=com"}, want: []string{"CN=A,DC=example,DC=com"}},
+		{flag: "ids", value: []any{1, 2}, want: []string{"1", "2"}},
+		{flag: "ids", value: []any{1, "two"}, wantErr: `invalid intSlice value "two"`},
+		{flag: "name", value: []any{"a", "b"}, wantErr: "does not accept a list"},
+		{flag: "name", value: map[string]any{"a": 1}, wantErr: "unsupported value"},
+		{flag: "name", value: nil, wantErr: "unsupported value"},
+		{flag: "member", value: []any{[]any{"alice"}}, wantErr: "unsupported value"},
+	}
+
+	for _, tt := range tests {
+		got, err := flagValues(flags.Lookup(tt.flag), tt.value)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("--%s %v: error %v, want %q", tt.flag, tt.value, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("--%s %v: %v", tt.flag, tt.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("--%s %v: got %v, want %v", tt.flag, tt.value, got, tt.want)
+		}
+	}
+}
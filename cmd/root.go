@@ -4,21 +4,35 @@ Copyright © 2024 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
 	gitlab "github.com/Cloud-for-You/devops-cli/cmd/gitlab"
+	audit "github.com/Cloud-for-You/devops-cli/pkg/audit"
+	logging "github.com/Cloud-for-You/devops-cli/pkg/logging"
+	secret "github.com/Cloud-for-You/devops-cli/pkg/secret"
 )
 
 var (
-	Debug      bool
-	configFile string
-	Command    string
-	Flags      map[string]string
+	Debug       bool
+	configFile  string
+	auditLog    string
+	auditSyslog bool
+	auditReason string
+	logLevel    string
+	logFormat   string
+	Command     string
+	// configErr is the error of reading the configuration file, it is returned
+	// by the command, initConfig itself cannot fail
+	configErr error
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -26,12 +40,36 @@ var rootCmd = &cobra.Command{
 	Use:                   "devops-cli",
 	Short:                 "Client for DEVOPS tools management",
 	DisableFlagsInUseLine: true,
-	Run: func(cmd *cobra.Command, args []string) {
+	SilenceUsage:          true,
+	SilenceErrors:         true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if configErr != nil {
+			return configErr
+		}
+		level := logLevel
+		if Debug {
+			level = "debug"
+		}
+		if err := logging.Setup(level, logFormat, os.Stderr); err != nil {
+			return err
+		}
+
+		// Reference na secrety (env:, file:, vault:) nahradime jejich hodnotou
+		if err := secret.ResolveFlags(cmd.Flags(), viper.GetString); err != nil {
+			return err
+		}
+		printFlags(cmd)
+		return setupAudit(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
 		if Command != "" {
-			executeCommandFromConfig(cmd)
-		} else {
-			fmt.Println("Run `devops-cli --help` for usage.")
+			return executeCommandFromConfig(cmd)
 		}
+		if viper.IsSet("command") {
+			slog.Warn("command in the configuration file is ignored, pass the file with --config to run it", "config", viper.ConfigFileUsed())
+		}
+		fmt.Println("Run `devops-cli --help` for usage.")
+		return nil
 	},
 }
 
@@ -39,16 +77,26 @@ func init() {
 	cobra.OnInitialize(initConfig)
 
 	rootCmd.AddCommand(gitlab.GitlabCmd)
-
-	rootCmd.PersistentFlags().BoolVarP(&Debug, "debug", "d", false, "Display debugging output in the console. (default: false)")
-	rootCmd.Flags().StringVar(&configFile, "config", "", "Configuration file from command and flags.")
+	rootCmd.AddCommand(runCmd)
+
+	rootCmd.PersistentFlags().BoolVarP(&Debug, "debug", "d", false, "Display debugging output in the console, same as --logLevel debug. (default: false)")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "logLevel", "info", "Minimal level of log messages (debug, info, warn, error).")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "logFormat", logging.FormatText, "Format of log messages written to stderr (text, json).")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Configuration file, default devops-cli.yaml in the current directory or ~/.config/devops-cli. Its command is run only when the file is given explicitly.")
+	rootCmd.PersistentFlags().StringVar(&auditLog, "auditLog", "", "Append a JSON line for every change made in GitLab to this file.")
+	viper.BindPFlag("auditLog", rootCmd.PersistentFlags().Lookup("auditLog"))
+	rootCmd.PersistentFlags().BoolVar(&auditSyslog, "auditSyslog", false, "Send audit records to the local syslog. (default: false)")
+	viper.BindPFlag("auditSyslog", rootCmd.PersistentFlags().Lookup("auditSyslog"))
+	rootCmd.PersistentFlags().StringVar(&auditReason, "auditReason", "", "Reason of the change (ticket, request), stored in the audit log.")
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
 	err := rootCmd.Execute()
+	audit.Default().Close()
 	if err != nil {
+		slog.Error(err.Error())
 		os.Exit(1)
 	}
 }
@@ -56,34 +104,78 @@ func Execute() {
 func initConfig() {
 	if configFile != "" {
 		viper.SetConfigFile(configFile)
+	} else {
+		// Bez --config hledame devops-cli.yaml v aktualnim adresari a v ~/.config/devops-cli
+		viper.SetConfigName("devops-cli")
+		viper.AddConfigPath(".")
+		if home, err := os.UserHomeDir(); err == nil {
+			viper.AddConfigPath(filepath.Join(home, ".config", "devops-cli"))
+		}
 	}
 
+	configErr = nil
 	if err := viper.ReadInConfig(); err != nil {
-		//fmt.Printf("Error reading config file: %v\n", err)
+		// Chybejici soubor vadi jen, pokud byl zadan pres --config
+		var notFound viper.ConfigFileNotFoundError
+		if configFile != "" || !errors.As(err, &notFound) {
+			configErr = fmt.Errorf("error reading configuration file: %w", err)
+		}
 		return
 	}
 
-	Command = viper.GetString("command")
-	Flags = viper.GetStringMapString("flags")
-	fmt.Println(Command)
+	// Prikaz spoustime jen z konfigurace zadane pres --config, nalezeny soubor
+	// (napr. devops-cli.yaml v cizim repozitari) muze obsahovat cokoliv
+	if configFile != "" {
+		Command = viper.GetString("command")
+	}
 }
 
-func executeCommandFromConfig(cmd *cobra.Command) {
-	parts := strings.Split(Command, "/")
-
-	// Pripravime argumenty pro prikaz
-	args := append([]string{parts[0]}, parts[1:]...)
+// executeCommandFromConfig runs the command and flags from the configuration file,
+// the file is read as a job file with a single job
+func executeCommandFromConfig(cmd *cobra.Command) error {
+	file, err := loadJobFile(viper.ConfigFileUsed())
+	if err != nil {
+		return err
+	}
 
-	for flag, value := range Flags {
-		args = append(args, fmt.Sprintf("--%s=\"%s\"", flag, value))
+	jobs, err := prepareJobs(cmd.Root(), file, nil)
+	if err != nil {
+		return err
 	}
+	return runJobs(cmd.Root(), jobs, false)
+}
 
-	// Nastavime argumenty prikazu
-	cmd.SetArgs(args)
-	fmt.Printf("Executing command: %s\n", strings.Join(args, " "))
+// printFlags logs effective flag values of the command, secrets are masked
+func printFlags(cmd *cobra.Command) {
+	attrs := []any{"command", cmd.CommandPath()}
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		attrs = append(attrs, slog.String("flag."+f.Name, secret.FlagValue(f)))
+	})
+	slog.Debug("effective flags", attrs...)
+}
 
-	// Spustime prikaz s argumenty
-	if err := cmd.Execute(); err != nil {
-		fmt.Printf("Error executing command: %v\n", err)
+// setupAudit enables the configured audit sinks for this run
+func setupAudit(cmd *cobra.Command, args []string) error {
+	logger := audit.Default()
+	// Sinky predchozi ulohy (devops-cli run) zavreme, kazda uloha si je nastavi znovu
+	logger.Close()
+	logger.SetContext(strings.Join(append([]string{cmd.CommandPath()}, args...), " "), auditReason)
+
+	if path := viper.GetString("auditLog"); path != "" {
+		sink, err := audit.NewFileSink(path)
+		if err != nil {
+			return err
+		}
+		logger.AddSink(sink)
 	}
+
+	if viper.GetBool("auditSyslog") {
+		sink, err := audit.NewSyslogSink("devops-cli")
+		if err != nil {
+			return err
+		}
+		logger.AddSink(sink)
+	}
+
+	return nil
 }
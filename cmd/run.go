@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	continueOnError bool
+	validateOnly    bool
+)
+
+// runCmd executes jobs from a job file
+var runCmd = &cobra.Command{
+	Use:   "run <jobfile> [job...]",
+	Short: "Run commands defined in a job file",
+	Long: `The "run" command executes jobs from a YAML job file one by one, or only the
+named jobs in the given order. All selected jobs are validated first (command exists,
+flags exist and have values of the right type, required flags are set), nothing runs
+when any job is invalid.
+
+Job file example:
+
+  continueOnError: false
+  jobs:
+    - name: corporate-groups
+      command: gitlab-ce groupsync ldap
+      flags:
+        gitlabUrl: https://gitlab.example.com
+        gitlabToken: env:GITLAB_TOKEN          # secret reference, resolved at run time
+        ldapHost: ldaps://secure.example.com
+        ldapBindDN: CN=manager,DC=example,DC=com
+        ldapPassword: vault:secret/ldap#password
+        ldapSearchBase:                        # list flags take a YAML list
+          - OU=Groups,DC=example,DC=com
+        ldapStartTLS: false
+    - name: team-group
+      command: gitlab-ce group create
+      flags:
+        profile: prod
+        name: team
+
+The older format with a single top-level "command" and "flags" is accepted as one job
+named "default".`,
+	Example: `  devops-cli run jobs.yaml
+  devops-cli run jobs.yaml team-group
+  devops-cli run jobs.yaml --validate`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runJobFile,
+}
+
+func init() {
+	runCmd.Flags().BoolVar(&continueOnError, "continueOnError", false, "run remaining jobs after a failed one (also continueOnError in the job file)")
+	runCmd.Flags().BoolVar(&validateOnly, "validate", false, "only validate the job file")
+}
+
+func runJobFile(cmd *cobra.Command, args []string) error {
+	file, err := loadJobFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	jobs, err := prepareJobs(cmd.Root(), file, args[1:])
+	if err != nil {
+		return err
+	}
+	if validateOnly {
+		slog.Info("job file is valid", "jobs", len(jobs))
+		return nil
+	}
+
+	return runJobs(cmd.Root(), jobs, continueOnError || file.ContinueOnError)
+}
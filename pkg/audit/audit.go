@@ -0,0 +1,167 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Result values of an Event
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Target identifies the object changed by an action
+type Target struct {
+	Type string `json:"type"`
+	ID   int    `json:"id,omitempty"`
+	Name string `json:"name"`
+}
+
+// Subject is the user whose membership is changed by an action
+type Subject struct {
+	ID       int    `json:"id,omitempty"`
+	Username string `json:"username"`
+}
+
+// Event is one record of the audit log
+type Event struct {
+	Time    time.Time   `json:"time"`
+	Actor   string      `json:"actor"`
+	Command string      `json:"command"`
+	Reason  string      `json:"reason,omitempty"`
+	Action  string      `json:"action"`
+	Target  Target      `json:"target"`
+	Subject *Subject    `json:"subject,omitempty"`
+	Before  interface{} `json:"before"`
+	After   interface{} `json:"after"`
+	Result  string      `json:"result"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// Sink stores audit events
+type Sink interface {
+	Write(event Event) error
+	Close() error
+}
+
+// Logger fills the context of the run (command, reason) into events and
+// writes them to all sinks. A Logger without sinks discards events.
+type Logger struct {
+	mu      sync.Mutex
+	sinks   []Sink
+	command string
+	reason  string
+}
+
+var std = &Logger{}
+
+// Default returns the process wide audit logger
+func Default() *Logger {
+	return std
+}
+
+// AddSink enables writing of events to the sink
+func (l *Logger) AddSink(sink Sink) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sinks = append(l.sinks, sink)
+}
+
+// SetContext sets the command line and the reason recorded with every event
+func (l *Logger) SetContext(command, reason string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.command = command
+	l.reason = reason
+}
+
+// Enabled reports whether events are stored anywhere. Callers use it to skip
+// work needed only for auditing, e.g. reading the state before a change.
+func (l *Logger) Enabled() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.sinks) > 0
+}
+
+// Record writes the event, err sets the failure result
+func (l *Logger) Record(event Event, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.sinks) == 0 {
+		return
+	}
+
+	event.Time = time.Now().UTC()
+	event.Command = l.command
+	event.Reason = l.reason
+	event.Result = ResultSuccess
+	if err != nil {
+		event.Result = ResultFailure
+		event.Error = err.Error()
+	}
+
+	for _, sink := range l.sinks {
+		// Audit nesmi tise selhat, chybu alespon vypiseme
+		if werr := sink.Write(event); werr != nil {
+			slog.Error("failed to write audit event", "error", werr)
+		}
+	}
+}
+
+// Close closes all sinks
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var errs []error
+	for _, sink := range l.sinks {
+		errs = append(errs, sink.Close())
+	}
+	l.sinks = nil
+	return errors.Join(errs...)
+}
+
+// Record writes the event to the default logger
+func Record(event Event, err error) {
+	std.Record(event, err)
+}
+
+// Enabled reports whether the default logger stores events
+func Enabled() bool {
+	return std.Enabled()
+}
+
+// FileSink appends events as JSON lines to a file
+type FileSink struct {
+	file *os.File
+}
+
+// NewFileSink opens the file for appending, it is created when missing
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("error opening audit log: %w", err)
+	}
+	return &FileSink{file: file}, nil
+}
+
+func (s *FileSink) Write(event Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	// Jeden zapis na udalost, O_APPEND zajisti, ze se radky neprolinaji
+	_, err = s.file.Write(append(line, '\n'))
+	return err
+}
+
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
@@ -0,0 +1,37 @@
+//go:build !windows && !plan9
+
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/syslog"
+)
+
+// SyslogSink sends events as JSON messages to the local syslog daemon
+type SyslogSink struct {
+	writer *syslog.Writer
+}
+
+func NewSyslogSink(tag string) (*SyslogSink, error) {
+	writer, err := syslog.New(syslog.LOG_NOTICE|syslog.LOG_AUTH, tag)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to syslog: %w", err)
+	}
+	return &SyslogSink{writer: writer}, nil
+}
+
+func (s *SyslogSink) Write(event Event) error {
+	message, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if event.Result == ResultFailure {
+		return s.writer.Warning(string(message))
+	}
+	return s.writer.Notice(string(message))
+}
+
+func (s *SyslogSink) Close() error {
+	return s.writer.Close()
+}
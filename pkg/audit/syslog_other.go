@@ -0,0 +1,20 @@
+//go:build windows || plan9
+
+package audit
+
+import "fmt"
+
+// SyslogSink is not available on this platform
+type SyslogSink struct{}
+
+func NewSyslogSink(tag string) (*SyslogSink, error) {
+	return nil, fmt.Errorf("syslog is not supported on this platform")
+}
+
+func (s *SyslogSink) Write(event Event) error {
+	return nil
+}
+
+func (s *SyslogSink) Close() error {
+	return nil
+}
@@ -1,43 +1,174 @@
 package common
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+	"time"
 
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// Member is a single member of a group as seen by one side of a sync
+// (GitLab or a source such as LDAP, Azure, File, etc...).
 type Member struct {
+	// Name is the GitLab username (or the source attribute mapped to it)
 	Name string
+	// Email is the primary e-mail address, if known
+	Email string
+	// ExternalID identifies the member in the source system (LDAP DN, Azure object ID, ...)
+	ExternalID string
+	// AccessLevel is the desired/current GitLab access level, 0 means "not managed"
+	AccessLevel gitlab.AccessLevelValue
+	// ExpiresAt is the membership expiration date. For a desired member nil means
+	// "not managed", unless CompareOptions.AuthoritativeExpiry is set.
+	ExpiresAt *time.Time
+	// Source names where the member came from (gitlab, ldap, file, ...)
+	Source string
+}
+
+// Key returns the identity used to match members between GitLab and a source.
+// GitLab usernames are case-insensitive, so the comparison is too.
+func (m Member) Key() string {
+	return strings.ToLower(m.Name)
+}
+
+type ChangeType string
+
+const (
+	ChangeAdd          ChangeType = "add"
+	ChangeRemove       ChangeType = "remove"
+	ChangeUpdateAccess ChangeType = "update-access"
+	ChangeUpdateExpiry ChangeType = "update-expiry"
+)
+
+// MemberChange is a single action needed to turn the current state into the desired one.
+// Member is the desired state (for ChangeRemove the current one), Current is set
+// for updates and holds the member as it is now in GitLab.
+type MemberChange struct {
+	Type    ChangeType
+	Member  Member
+	Current *Member
+}
+
+// Changeset is an ordered list of changes produced by CompareMembers
+type Changeset []MemberChange
+
+// OfType returns only changes of the given type
+func (c Changeset) OfType(t ChangeType) Changeset {
+	var out Changeset
+	for _, change := range c {
+		if change.Type == t {
+			out = append(out, change)
+		}
+	}
+	return out
+}
+
+// Members returns the members of all changes of the given type
+func (c Changeset) Members(t ChangeType) []Member {
+	var out []Member
+	for _, change := range c.OfType(t) {
+		out = append(out, change.Member)
+	}
+	return out
+}
+
+// Empty reports whether there is nothing to do
+func (c Changeset) Empty() bool {
+	return len(c) == 0
+}
+
+// CompareOptions changes how CompareMembers treats desired members
+type CompareOptions struct {
+	// AuthoritativeExpiry makes a nil ExpiresAt of a desired member mean "no expiration",
+	// so an expiration set in GitLab is removed. Sources without expirations (LDAP)
+	// leave it unset and never touch expirations set manually in GitLab.
+	AuthoritativeExpiry bool
 }
 
 // currentMembers is members in GitLab group
 // desiredMembers is members in SRC group (LDAP, Azure, File, etc...)
-// missing: User is LDAP but not in GitLab (Create)
-// extra: User in GitLab but not in LDAP (Delete)
-func CompareMembers(gitlabMembers, sourceMembers []Member) (missing, extra []Member) {
+// add: User is in source but not in GitLab (Create)
+// remove: User in GitLab but not in source (Delete)
+// update-access: User is on both sides, source requests a different access level
+// update-expiry: User is on both sides, source requests a different expiration
+//
+// An AccessLevel of 0 on the desired member means the source does not manage
+// access levels, so no update-access change is produced for it. The same holds
+// for a nil ExpiresAt, see CompareMembersWithOptions.
+func CompareMembers(currentMembers, desiredMembers []Member) Changeset {
+	return CompareMembersWithOptions(currentMembers, desiredMembers, CompareOptions{})
+}
+
+// CompareMembersWithOptions is CompareMembers with options
+func CompareMembersWithOptions(currentMembers, desiredMembers []Member, options CompareOptions) Changeset {
+	var changes Changeset
+
 	// Vytvorime mapy pro rychle vyhledavani
-	gitlabSet := make(map[string]struct{})
-	srcSet := make(map[string]struct{})
+	currentSet := make(map[string]Member, len(currentMembers))
+	desiredSet := make(map[string]struct{}, len(desiredMembers))
 
-	// Naplnime mapu
-	for _, m := range gitlabMembers {
-		gitlabSet[m.Name] = struct{}{}
-	}
-	for _, m := range sourceMembers {
-		srcSet[m.Name] = struct{}{}
+	for _, m := range currentMembers {
+		currentSet[m.Key()] = m
 	}
 
-	// Najdeme chybejici cleny (v source, ale ne v GitLab)
-	for _, m := range sourceMembers {
-		if _, exists := gitlabSet[m.Name]; !exists {
-			missing = append(missing, m)
+	for _, m := range desiredMembers {
+		if _, seen := desiredSet[m.Key()]; seen {
+			continue
+		}
+		desiredSet[m.Key()] = struct{}{}
+
+		current, exists := currentSet[m.Key()]
+		if !exists {
+			changes = append(changes, MemberChange{Type: ChangeAdd, Member: m})
+			continue
+		}
+
+		if m.AccessLevel != 0 && m.AccessLevel != current.AccessLevel {
+			changes = append(changes, MemberChange{Type: ChangeUpdateAccess, Member: m, Current: &current})
+		}
+		// Bez expirace ve zdroji ji spravujeme jen na vyzadani
+		managed := m.ExpiresAt != nil || options.AuthoritativeExpiry
+		if managed && !SameDate(m.ExpiresAt, current.ExpiresAt) {
+			changes = append(changes, MemberChange{Type: ChangeUpdateExpiry, Member: m, Current: &current})
 		}
 	}
-	fmt.Printf("Created: %v\n", missing)
 
 	// Najdeme prebyvajici cleny (v GitLab, ale ne v source)
-	for _, m := range gitlabMembers {
-		if _, exists := srcSet[m.Name]; !exists {
-			extra = append(extra, m)
+	for _, m := range currentMembers {
+		if _, exists := desiredSet[m.Key()]; !exists {
+			changes = append(changes, MemberChange{Type: ChangeRemove, Member: m})
 		}
 	}
-	fmt.Printf("Deleted: %v\n", extra)
 
-	return missing, extra
+	return changes
+}
+
+// NoExpiry is the value of an expiration date meaning no expiration
+const NoExpiry = "never"
+
+// ParseDate parses an expiration date in the YYYY-MM-DD format,
+// an empty value and "never" mean no expiration
+func ParseDate(value string) (*time.Time, error) {
+	if value == "" || value == NoExpiry {
+		return nil, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expiration date %q, use YYYY-MM-DD", value)
+	}
+	return &t, nil
+}
+
+// FormatDate formats an expiration date, "never" when there is none
+func FormatDate(t *time.Time) string {
+	if t == nil {
+		return NoExpiry
+	}
+	return t.Format(time.DateOnly)
+}
+
+// SameDate compares expiration dates with day precision, GitLab stores only the date
+func SameDate(a, b *time.Time) bool {
+	return FormatDate(a) == FormatDate(b)
 }
@@ -0,0 +1,155 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+func TestCompareMembers(t *testing.T) {
+	date := func(s string) *time.Time {
+		d, err := time.Parse(time.DateOnly, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &d
+	}
+	type change struct {
+		Type ChangeType
+		Name string
+	}
+
+	tests := []struct {
+		name          string
+		current       []Member
+		desired       []Member
+		authoritative bool
+		want          []change
+	}{
+		{
+			name:    "add and remove",
+			current: []Member{{Name: "alice"}, {Name: "bob"}},
+			desired: []Member{{Name: "bob"}, {Name: "carol"}},
+			want:    []change{{ChangeAdd, "carol"}, {ChangeRemove, "alice"}},
+		},
+		{
+			name:    "usernames are case-insensitive",
+			current: []Member{{Name: "Alice", AccessLevel: gitlab.DeveloperPermissions}},
+			desired: []Member{{Name: "alice", AccessLevel: gitlab.DeveloperPermissions}},
+		},
+		{
+			name:    "duplicate desired member",
+			desired: []Member{{Name: "alice"}, {Name: "ALICE"}},
+			want:    []change{{ChangeAdd, "alice"}},
+		},
+		{
+			name:    "access level",
+			current: []Member{{Name: "alice", AccessLevel: gitlab.DeveloperPermissions}},
+			desired: []Member{{Name: "alice", AccessLevel: gitlab.MaintainerPermissions}},
+			want:    []change{{ChangeUpdateAccess, "alice"}},
+		},
+		{
+			name:    "unmanaged access level",
+			current: []Member{{Name: "alice", AccessLevel: gitlab.OwnerPermissions}},
+			desired: []Member{{Name: "alice"}},
+		},
+		{
+			name:    "changed expiry",
+			current: []Member{{Name: "alice", ExpiresAt: date("2026-01-31")}},
+			desired: []Member{{Name: "alice", ExpiresAt: date("2026-12-31")}},
+			want:    []change{{ChangeUpdateExpiry, "alice"}},
+		},
+		{
+			name:    "new expiry",
+			current: []Member{{Name: "alice"}},
+			desired: []Member{{Name: "alice", ExpiresAt: date("2026-12-31")}},
+			want:    []change{{ChangeUpdateExpiry, "alice"}},
+		},
+		{
+			name:    "same expiry date with different time",
+			current: []Member{{Name: "alice", ExpiresAt: date("2026-12-31")}},
+			desired: []Member{{Name: "alice", ExpiresAt: func() *time.Time { d := date("2026-12-31").Add(5 * time.Hour); return &d }()}},
+		},
+		{
+			name:    "unmanaged expiry is kept",
+			current: []Member{{Name: "alice", ExpiresAt: date("2026-12-31")}},
+			desired: []Member{{Name: "alice"}},
+		},
+		{
+			name:          "authoritative expiry is removed",
+			current:       []Member{{Name: "alice", ExpiresAt: date("2026-12-31")}},
+			desired:       []Member{{Name: "alice"}},
+			authoritative: true,
+			want:          []change{{ChangeUpdateExpiry, "alice"}},
+		},
+		{
+			name:          "authoritative without expiry",
+			current:       []Member{{Name: "alice"}},
+			desired:       []Member{{Name: "alice"}},
+			authoritative: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []change
+			for _, c := range CompareMembersWithOptions(tt.current, tt.desired, CompareOptions{AuthoritativeExpiry: tt.authoritative}) {
+				got = append(got, change{c.Type, c.Member.Name})
+				if c.Type == ChangeUpdateAccess || c.Type == ChangeUpdateExpiry {
+					if c.Current == nil {
+						t.Errorf("%s of %s without current member", c.Type, c.Member.Name)
+					}
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	for _, value := range []string{"", NoExpiry} {
+		if got, err := ParseDate(value); got != nil || err != nil {
+			t.Errorf("ParseDate(%q) = %v, %v, want no expiration", value, got, err)
+		}
+	}
+
+	got, err := ParseDate("2026-12-31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if FormatDate(got) != "2026-12-31" {
+		t.Errorf("got %s, want 2026-12-31", FormatDate(got))
+	}
+
+	for _, value := range []string{"31.12.2026", "2026-12-31T00:00:00Z", "tomorrow"} {
+		if _, err := ParseDate(value); err == nil {
+			t.Errorf("ParseDate(%q) without error", value)
+		}
+	}
+}
+
+func TestSameDate(t *testing.T) {
+	day := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+	evening := day.Add(20 * time.Hour)
+	next := day.AddDate(0, 0, 1)
+
+	tests := []struct {
+		a, b *time.Time
+		want bool
+	}{
+		{nil, nil, true},
+		{&day, nil, false},
+		{nil, &day, false},
+		{&day, &evening, true},
+		{&day, &next, false},
+	}
+	for _, tt := range tests {
+		if got := SameDate(tt.a, tt.b); got != tt.want {
+			t.Errorf("SameDate(%s, %s) = %t, want %t", FormatDate(tt.a), FormatDate(tt.b), got, tt.want)
+		}
+	}
+}
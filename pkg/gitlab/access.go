@@ -0,0 +1,54 @@
+package gitlab
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// accessLevels are the access level names accepted by ParseAccessLevel
+var accessLevels = map[string]gitlab.AccessLevelValue{
+	"guest":      gitlab.GuestPermissions,
+	"reporter":   gitlab.ReporterPermissions,
+	"developer":  gitlab.DeveloperPermissions,
+	"maintainer": gitlab.MaintainerPermissions,
+	"owner":      gitlab.OwnerPermissions,
+}
+
+// ParseAccessLevel converts a role name (guest, reporter, developer, maintainer,
+// owner) or its numeric value to the access level
+func ParseAccessLevel(value string) (gitlab.AccessLevelValue, error) {
+	if level, ok := accessLevels[strings.ToLower(strings.TrimSpace(value))]; ok {
+		return level, nil
+	}
+	if number, err := strconv.Atoi(value); err == nil {
+		for _, level := range accessLevels {
+			if int(level) == number {
+				return level, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("invalid access level %q (supported: guest, reporter, developer, maintainer, owner)", value)
+}
+
+// AccessLevelName returns the role name of the access level
+func AccessLevelName(level gitlab.AccessLevelValue) string {
+	switch {
+	case level >= gitlab.AdminPermissions:
+		return "admin"
+	case level >= gitlab.OwnerPermissions:
+		return "owner"
+	case level >= gitlab.MaintainerPermissions:
+		return "maintainer"
+	case level >= gitlab.DeveloperPermissions:
+		return "developer"
+	case level >= gitlab.ReporterPermissions:
+		return "reporter"
+	case level >= gitlab.GuestPermissions:
+		return "guest"
+	default:
+		return "no"
+	}
+}
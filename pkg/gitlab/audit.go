@@ -0,0 +1,116 @@
+package gitlab
+
+import (
+	"sync"
+
+	"github.com/Cloud-for-You/devops-cli/pkg/audit"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// memberState is the audited state of a group membership
+type memberState struct {
+	Username    string                  `json:"username"`
+	AccessLevel gitlab.AccessLevelValue `json:"accessLevel"`
+	ExpiresAt   *gitlab.ISOTime         `json:"expiresAt,omitempty"`
+}
+
+// objectState is the audited state of a group or project
+type objectState struct {
+	ID          int    `json:"id"`
+	FullPath    string `json:"fullPath"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Visibility  string `json:"visibility"`
+	WebURL      string `json:"webUrl"`
+}
+
+// shareState is the audited state of a group or project shared with a group
+type shareState struct {
+	Group       string                  `json:"group"`
+	AccessLevel gitlab.AccessLevelValue `json:"accessLevel"`
+	ExpiresAt   *gitlab.ISOTime         `json:"expiresAt,omitempty"`
+}
+
+// commitState is the audited commit created by the tool
+type commitState struct {
+	Branch string `json:"branch"`
+	ID     string `json:"id"`
+	Files  int    `json:"files"`
+}
+
+var (
+	actorsMu sync.Mutex
+	actors   = map[*gitlab.Client]string{}
+)
+
+// auditActor returns the username owning the client token, it is looked up once per client
+func auditActor(client *gitlab.Client) string {
+	actorsMu.Lock()
+	defer actorsMu.Unlock()
+
+	if actor, ok := actors[client]; ok {
+		return actor
+	}
+
+	actor := "unknown"
+	if username, err := Whoami(client); err == nil {
+		actor = *username
+	}
+	actors[client] = actor
+	return actor
+}
+
+// recordAudit stores the event when auditing is enabled
+func recordAudit(client *gitlab.Client, event audit.Event, err error) {
+	if !audit.Enabled() {
+		return
+	}
+	event.Actor = auditActor(client)
+	audit.Record(event, err)
+}
+
+// groupMemberState returns the current membership, nil when the user is not a direct member
+func groupMemberState(client *gitlab.Client, groupID interface{}, userID int) *memberState {
+	if !audit.Enabled() {
+		return nil
+	}
+	member, _, err := client.GroupMembers.GetGroupMember(groupID, userID)
+	if err != nil {
+		return nil
+	}
+	return &memberState{Username: member.Username, AccessLevel: member.AccessLevel, ExpiresAt: member.ExpiresAt}
+}
+
+// projectMemberState returns the current project membership, nil when the user is not a direct member
+func projectMemberState(client *gitlab.Client, projectID interface{}, userID int) *memberState {
+	if !audit.Enabled() {
+		return nil
+	}
+	member, _, err := client.ProjectMembers.GetProjectMember(projectID, userID)
+	if err != nil {
+		return nil
+	}
+	return &memberState{Username: member.Username, AccessLevel: member.AccessLevel, ExpiresAt: member.ExpiresAt}
+}
+
+func groupState(group *gitlab.Group) *objectState {
+	return &objectState{
+		ID:          group.ID,
+		FullPath:    group.FullPath,
+		Name:        group.Name,
+		Description: group.Description,
+		Visibility:  string(group.Visibility),
+		WebURL:      group.WebURL,
+	}
+}
+
+func projectState(project *gitlab.Project) *objectState {
+	return &objectState{
+		ID:          project.ID,
+		FullPath:    project.PathWithNamespace,
+		Name:        project.Name,
+		Description: project.Description,
+		Visibility:  string(project.Visibility),
+		WebURL:      project.WebURL,
+	}
+}
@@ -0,0 +1,247 @@
+package gitlab
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cleanhttp"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+
+	"github.com/Cloud-for-You/devops-cli/pkg/metrics"
+)
+
+// DefaultUserAgent identifies requests sent by devops-cli
+const DefaultUserAgent = "devops-cli"
+
+// Podporovane metody autentizace vuci GitLabu
+const (
+	// AuthToken is a personal, group or project access token
+	AuthToken = "token"
+	// AuthOAuth2 is an OAuth2 access token, given directly or stored by "login"
+	AuthOAuth2 = "oauth2"
+	// AuthJobToken is CI_JOB_TOKEN of a running GitLab CI job
+	AuthJobToken = "job-token"
+	// AuthBasic exchanges username and password for an OAuth2 token
+	AuthBasic = "basic"
+)
+
+// ClientOptions configures connection to the GitLab API
+type ClientOptions struct {
+	URL string
+
+	// AuthMethod is one of AuthToken (default), AuthOAuth2, AuthJobToken, AuthBasic
+	AuthMethod string
+	Token      string
+	// Username and Password of AuthBasic
+	Username string
+	Password string
+	// OAuthClientID is the application ID used to refresh stored OAuth2 tokens
+	OAuthClientID string
+	// TokenFile stores OAuth2 tokens, used by AuthOAuth2 when Token is empty
+	TokenFile string
+
+	// TLS
+	CACert             string
+	ClientCert         string
+	ClientKey          string
+	InsecureSkipVerify bool
+
+	// Proxy overrides HTTP(S)_PROXY environment variables
+	Proxy string
+	// Timeout of a single HTTP request, zero means no timeout
+	Timeout time.Duration
+
+	// Retries is the maximum number of retries, zero keeps the client default
+	// and negative value disables retrying
+	Retries      int
+	RetryWaitMin time.Duration
+	RetryWaitMax time.Duration
+
+	// UserAgent is sent with every request, default DefaultUserAgent
+	UserAgent string
+	// Trace logs every API request and response status at debug level
+	Trace bool
+}
+
+// NewClient creates GitLab API client authenticated by the selected method.
+// All clients record API metrics.
+func NewClient(options ClientOptions) (*gitlab.Client, error) {
+	if options.URL == "" {
+		return nil, fmt.Errorf("GitLab URL must be provided")
+	}
+
+	httpClient, err := HTTPClient(options)
+	if err != nil {
+		return nil, err
+	}
+
+	clientOptions := []gitlab.ClientOptionFunc{
+		gitlab.WithBaseURL(options.URL),
+		gitlab.WithHTTPClient(httpClient),
+	}
+	switch {
+	case options.Retries < 0:
+		clientOptions = append(clientOptions, gitlab.WithoutRetries())
+	case options.Retries > 0:
+		clientOptions = append(clientOptions, gitlab.WithCustomRetryMax(options.Retries))
+	}
+	if options.RetryWaitMin > 0 || options.RetryWaitMax > 0 {
+		waitMin, waitMax := options.RetryWaitMin, options.RetryWaitMax
+		if waitMax < waitMin {
+			waitMax = waitMin
+		}
+		clientOptions = append(clientOptions, gitlab.WithCustomRetryWaitMinMax(waitMin, waitMax))
+	}
+
+	var client *gitlab.Client
+	switch options.authMethod() {
+	case AuthToken:
+		if options.Token == "" {
+			return nil, fmt.Errorf("GitLab token must be provided")
+		}
+		client, err = gitlab.NewClient(options.Token, clientOptions...)
+	case AuthJobToken:
+		token := options.Token
+		if token == "" {
+			token = os.Getenv("CI_JOB_TOKEN")
+		}
+		if token == "" {
+			return nil, fmt.Errorf("job token authentication requires a token or CI_JOB_TOKEN")
+		}
+		client, err = gitlab.NewJobClient(token, clientOptions...)
+	case AuthBasic:
+		// client-go vymeni jmeno a heslo za OAuth2 token (password grant)
+		if options.Username == "" || options.Password == "" {
+			return nil, fmt.Errorf("basic authentication requires username and password")
+		}
+		client, err = gitlab.NewBasicAuthClient(options.Username, options.Password, clientOptions...)
+	case AuthOAuth2:
+		client, err = newOAuthClient(options, httpClient, clientOptions)
+	default:
+		return nil, fmt.Errorf("unsupported authentication method %q (supported: %s, %s, %s, %s)",
+			options.AuthMethod, AuthToken, AuthOAuth2, AuthJobToken, AuthBasic)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GitLab client: %w", err)
+	}
+
+	client.UserAgent = options.UserAgent
+	if client.UserAgent == "" {
+		client.UserAgent = DefaultUserAgent
+	}
+
+	return client, nil
+}
+
+// HTTPClient returns HTTP client with TLS, proxy and timeout settings of options
+func HTTPClient(options ClientOptions) (*http.Client, error) {
+	transport := cleanhttp.DefaultPooledTransport()
+
+	tlsConfig, err := options.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	if options.Proxy != "" {
+		proxy, err := url.Parse(options.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL %q: %w", options.Proxy, err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	var roundTripper http.RoundTripper = transport
+	if options.Trace {
+		roundTripper = traceTransport(roundTripper)
+	}
+
+	return &http.Client{
+		Transport: metrics.InstrumentTransport(roundTripper),
+		Timeout:   options.Timeout,
+	}, nil
+}
+
+func (o ClientOptions) authMethod() string {
+	if o.AuthMethod == "" {
+		return AuthToken
+	}
+	return strings.ToLower(o.AuthMethod)
+}
+
+// tlsConfig builds TLS settings from CA bundle and client certificate
+func (o ClientOptions) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+
+	if o.CACert != "" {
+		pem, err := os.ReadFile(o.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("error reading CA certificate: %w", err)
+		}
+		// Vlastni CA pridame k systemovym, aby fungovaly i verejne certifikaty
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", o.CACert)
+		}
+		config.RootCAs = pool
+	}
+
+	if o.ClientCert != "" || o.ClientKey != "" {
+		if o.ClientCert == "" || o.ClientKey == "" {
+			return nil, fmt.Errorf("client certificate and key must be provided together")
+		}
+		cert, err := tls.LoadX509KeyPair(o.ClientCert, o.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// traceTransport logs requests without headers, so tokens never reach the log
+func traceTransport(next http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		start := time.Now()
+		res, err := next.RoundTrip(req)
+
+		attrs := []any{"method", req.Method, "url", req.URL.Redacted(), "duration", time.Since(start)}
+		if err != nil {
+			slog.Debug("GitLab API request failed", append(attrs, "error", err)...)
+			return res, err
+		}
+		slog.Debug("GitLab API request", append(attrs, "status", res.StatusCode)...)
+		return res, nil
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// IsNotFound reports whether the API returned 404, the client returns ErrNotFound
+// for it instead of an ErrorResponse
+func IsNotFound(err error) bool {
+	var errResponse *gitlab.ErrorResponse
+	if errors.As(err, &errResponse) {
+		return errResponse.Response.StatusCode == http.StatusNotFound
+	}
+	return errors.Is(err, gitlab.ErrNotFound)
+}
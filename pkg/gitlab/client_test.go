@@ -0,0 +1,205 @@
+package gitlab
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewClientAuth(t *testing.T) {
+	headers := http.Header{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth/token":
+			// Basic autentizace vymeni jmeno a heslo za OAuth2 token
+			if err := r.ParseForm(); err != nil || r.Form.Get("username") != "alice" || r.Form.Get("password") != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error": "invalid_grant"}`))
+				return
+			}
+			w.Write([]byte(`{"access_token": "password-access", "token_type": "Bearer", "expires_in": 7200}`))
+		case "/api/v4/user":
+			headers = r.Header.Clone()
+			w.Write([]byte(`{"id": 1, "username": "alice"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "404 Not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		options    ClientOptions
+		jobToken   string
+		wantHeader string
+		wantValue  string
+		wantErr    string
+	}{
+		{name: "token is the default", options: ClientOptions{Token: "glpat"}, wantHeader: "Private-Token", wantValue: "glpat"},
+		{name: "token", options: ClientOptions{AuthMethod: "Token", Token: "glpat"}, wantHeader: "Private-Token", wantValue: "glpat"},
+		{name: "token missing", options: ClientOptions{AuthMethod: AuthToken}, wantErr: "GitLab token must be provided"},
+		{name: "oauth2 token", options: ClientOptions{AuthMethod: AuthOAuth2, Token: "access"}, wantHeader: "Authorization", wantValue: "Bearer access"},
+		{name: "job token", options: ClientOptions{AuthMethod: AuthJobToken, Token: "job"}, wantHeader: "Job-Token", wantValue: "job"},
+		{name: "job token from CI", options: ClientOptions{AuthMethod: AuthJobToken}, jobToken: "ci-job", wantHeader: "Job-Token", wantValue: "ci-job"},
+		{name: "job token missing", options: ClientOptions{AuthMethod: AuthJobToken}, wantErr: "requires a token or CI_JOB_TOKEN"},
+		{name: "basic", options: ClientOptions{AuthMethod: AuthBasic, Username: "alice", Password: "secret"}, wantHeader: "Authorization", wantValue: "Bearer password-access"},
+		{name: "basic without password", options: ClientOptions{AuthMethod: AuthBasic, Username: "alice"}, wantErr: "requires username and password"},
+		{name: "unsupported method", options: ClientOptions{AuthMethod: "kerberos", Token: "glpat"}, wantErr: `unsupported authentication method "kerberos"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CI_JOB_TOKEN", tt.jobToken)
+			tt.options.URL = server.URL
+			tt.options.Retries = -1
+
+			client, err := NewClient(tt.options)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if client.UserAgent != DefaultUserAgent {
+				t.Errorf("user agent %q, want %q", client.UserAgent, DefaultUserAgent)
+			}
+
+			headers = http.Header{}
+			if _, _, err := client.Users.CurrentUser(); err != nil {
+				t.Fatal(err)
+			}
+			if got := headers.Get(tt.wantHeader); got != tt.wantValue {
+				t.Errorf("%s %q, want %q", tt.wantHeader, got, tt.wantValue)
+			}
+		})
+	}
+
+	if _, err := NewClient(ClientOptions{Token: "glpat"}); err == nil || !strings.Contains(err.Error(), "GitLab URL must be provided") {
+		t.Errorf("without URL: error %v", err)
+	}
+}
+
+// writeCertificate writes a self-signed certificate and its key as PEM files
+func writeCertificate(t *testing.T, dir, name string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	caFile, caKeyFile := writeCertificate(t, dir, "ca")
+	certFile, keyFile := writeCertificate(t, dir, "client")
+	notPEM := filepath.Join(dir, "ca.txt")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("CA is added to the system pool", func(t *testing.T) {
+		config, err := ClientOptions{CACert: caFile}.tlsConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := x509.SystemCertPool()
+		if err != nil || want == nil {
+			want = x509.NewCertPool()
+		}
+		caPEM, err := os.ReadFile(caFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want.AppendCertsFromPEM(caPEM)
+		if !config.RootCAs.Equal(want) {
+			t.Error("root CAs are not the system pool with the CA certificate")
+		}
+		if config.MinVersion != tls.VersionTLS12 {
+			t.Errorf("min version %x, want TLS 1.2", config.MinVersion)
+		}
+	})
+
+	t.Run("system pool without CA", func(t *testing.T) {
+		config, err := ClientOptions{InsecureSkipVerify: true}.tlsConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if config.RootCAs != nil || !config.InsecureSkipVerify {
+			t.Errorf("got %+v", config)
+		}
+	})
+
+	t.Run("client certificate", func(t *testing.T) {
+		config, err := ClientOptions{ClientCert: certFile, ClientKey: keyFile}.tlsConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(config.Certificates) != 1 {
+			t.Errorf("got %d client certificates, want 1", len(config.Certificates))
+		}
+	})
+
+	invalid := []struct {
+		name    string
+		options ClientOptions
+		wantErr string
+	}{
+		{name: "missing CA", options: ClientOptions{CACert: filepath.Join(dir, "missing.crt")}, wantErr: "error reading CA certificate"},
+		{name: "CA without certificates", options: ClientOptions{CACert: notPEM}, wantErr: "no certificates found"},
+		{name: "certificate without key", options: ClientOptions{ClientCert: certFile}, wantErr: "must be provided together"},
+		{name: "key without certificate", options: ClientOptions{ClientKey: keyFile}, wantErr: "must be provided together"},
+		{name: "key of another certificate", options: ClientOptions{ClientCert: certFile, ClientKey: caKeyFile}, wantErr: "error loading client certificate"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.options.tlsConfig()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package declarative
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	gitlabapi "github.com/Cloud-for-You/devops-cli/pkg/gitlab"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// FailedAction is an action that could not be applied
+type FailedAction struct {
+	Action Action `json:"action"`
+	Error  string `json:"error"`
+}
+
+// Result is the outcome of applying a Plan
+type Result struct {
+	Started  time.Time      `json:"started"`
+	Finished time.Time      `json:"finished"`
+	Applied  []Action       `json:"applied"`
+	Errors   []FailedAction `json:"errors,omitempty"`
+	// Skipped are actions on groups and projects whose creation failed
+	Skipped []Action `json:"skipped,omitempty"`
+}
+
+// Failed reports whether any action failed or was skipped
+func (r *Result) Failed() bool {
+	return len(r.Errors) > 0 || len(r.Skipped) > 0
+}
+
+// Apply executes the plan. A failed action does not stop the run, only actions
+// depending on a group or project that could not be created are skipped.
+func Apply(client *gitlab.Client, plan *Plan, logger *slog.Logger) *Result {
+	result := &Result{Started: time.Now()}
+	var notCreated []string
+
+	for _, action := range plan.Actions {
+		attrs := []any{"action", action.Type, "kind", action.Kind, "path", action.Path}
+		if action.Target != "" {
+			attrs = append(attrs, "target", action.Target)
+		}
+
+		if dependsOn(action, notCreated) {
+			result.Skipped = append(result.Skipped, action)
+			logger.Warn("action skipped, group or project was not created", attrs...)
+			continue
+		}
+
+		if err := action.apply(client); err != nil {
+			result.Errors = append(result.Errors, FailedAction{Action: action, Error: err.Error()})
+			logger.Error("failed to apply action", append(attrs, "error", err)...)
+			if action.Type == ActionCreate {
+				notCreated = append(notCreated, action.Path)
+			}
+			continue
+		}
+		result.Applied = append(result.Applied, action)
+		logger.Info("action applied", attrs...)
+	}
+
+	result.Finished = time.Now()
+	return result
+}
+
+// dependsOn reports whether the action changes an object that was not created,
+// or an object inside it, or shares with it
+func dependsOn(action Action, notCreated []string) bool {
+	for _, path := range notCreated {
+		for _, p := range []string{action.Path, action.Target} {
+			if strings.EqualFold(p, path) || strings.HasPrefix(strings.ToLower(p), strings.ToLower(path)+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func createGroup(client *gitlab.Client, g desiredGroup) error {
+	name := g.name
+	if name == "" {
+		name = g.path
+	}
+	options := &gitlab.CreateGroupOptions{
+		Name:        gitlab.Ptr(name),
+		Path:        gitlab.Ptr(g.path),
+		Description: g.description,
+	}
+	if g.visibility != "" {
+		options.Visibility = gitlab.Ptr(gitlab.VisibilityValue(g.visibility))
+	}
+	// Nadrazena skupina mohla vzniknout az pri tomto behu, ID zjistime az ted
+	if g.parent != "" {
+		parent, err := gitlabapi.GetGroup(client, g.parent)
+		if err != nil {
+			return fmt.Errorf("error retrieving parent group '%s': %w", g.parent, err)
+		}
+		options.ParentID = gitlab.Ptr(parent.ID)
+	}
+
+	_, _, err := gitlabapi.CreateGroupWithOptions(client, options)
+	return err
+}
+
+func createProject(client *gitlab.Client, d desiredProject) error {
+	namespace, err := gitlabapi.GetNamespace(client, d.namespace)
+	if err != nil {
+		return err
+	}
+
+	name := d.name
+	if name == "" {
+		name = d.path
+	}
+	options := &gitlab.CreateProjectOptions{
+		Name:        gitlab.Ptr(name),
+		Path:        gitlab.Ptr(d.path),
+		NamespaceID: gitlab.Ptr(namespace.ID),
+		Description: d.description,
+	}
+	if d.visibility != "" {
+		options.Visibility = gitlab.Ptr(gitlab.VisibilityValue(d.visibility))
+	}
+	d.settings.CreateOptions(options)
+
+	_, _, err = gitlabapi.CreateProjectWithOptions(client, options)
+	return err
+}
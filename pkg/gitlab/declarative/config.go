@@ -0,0 +1,291 @@
+package declarative
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	common "github.com/Cloud-for-You/devops-cli/pkg"
+	gitlabapi "github.com/Cloud-for-You/devops-cli/pkg/gitlab"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// Config is the desired state of groups and projects. Lists of members and
+// shares are authoritative when present, an omitted list is not managed.
+type Config struct {
+	Groups   []Group   `yaml:"groups"`
+	Projects []Project `yaml:"projects"`
+}
+
+// Group is a group with its subgroups. Path of a top-level entry is the full
+// path of the group, path of a subgroup is relative to its parent.
+type Group struct {
+	Path        string   `yaml:"path"`
+	Name        string   `yaml:"name"`
+	Description *string  `yaml:"description"`
+	Visibility  string   `yaml:"visibility"`
+	Members     []Member `yaml:"members"`
+	SharedWith  []Share  `yaml:"sharedWith"`
+	Subgroups   []Group  `yaml:"subgroups"`
+}
+
+// Project is a project given by its full path
+type Project struct {
+	Path        string                    `yaml:"path"`
+	Name        string                    `yaml:"name"`
+	Description *string                   `yaml:"description"`
+	Visibility  string                    `yaml:"visibility"`
+	Members     []Member                  `yaml:"members"`
+	SharedWith  []Share                   `yaml:"sharedWith"`
+	Settings    gitlabapi.ProjectSettings `yaml:"settings"`
+}
+
+// Member is a direct member of a group or project
+type Member struct {
+	Username    string `yaml:"username"`
+	AccessLevel string `yaml:"accessLevel"`
+	ExpiresAt   string `yaml:"expiresAt"`
+}
+
+// Share is a group the group or project is shared with
+type Share struct {
+	Group       string `yaml:"group"`
+	AccessLevel string `yaml:"accessLevel"`
+	ExpiresAt   string `yaml:"expiresAt"`
+}
+
+// Load reads the configuration from a file, "-" reads the standard input
+func Load(filename string) (*Config, error) {
+	var data []byte
+	var err error
+	if filename == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(filename)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", filename, err)
+	}
+
+	var config Config
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	// Preklep v klici by jinak zpusobil tichou ztratu nastaveni
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("error parsing %s: %w", filename, err)
+	}
+	return &config, nil
+}
+
+// desiredGroup is a validated group with the full path
+type desiredGroup struct {
+	fullPath    string
+	parent      string
+	path        string
+	name        string
+	description *string
+	visibility  string
+	members     []common.Member
+	shares      []desiredShare
+	// Nil seznamy nejsou spravovane
+	manageMembers bool
+	manageShares  bool
+}
+
+// desiredProject is a validated project
+type desiredProject struct {
+	fullPath      string
+	namespace     string
+	path          string
+	name          string
+	description   *string
+	visibility    string
+	settings      gitlabapi.ProjectSettings
+	members       []common.Member
+	shares        []desiredShare
+	manageMembers bool
+	manageShares  bool
+}
+
+type desiredShare struct {
+	group       string
+	accessLevel gitlab.AccessLevelValue
+	expiresAt   *time.Time
+}
+
+// resolve validates the configuration and flattens nested groups
+func (c *Config) resolve() ([]desiredGroup, []desiredProject, error) {
+	var groups []desiredGroup
+	var projects []desiredProject
+	var errs []error
+	seen := map[string]struct{}{}
+
+	var walk func(parent string, list []Group)
+	walk = func(parent string, list []Group) {
+		for _, g := range list {
+			fullPath := strings.Trim(g.Path, "/")
+			if parent != "" {
+				fullPath = parent + "/" + fullPath
+			}
+			desired, err := resolveGroup(parent, fullPath, g)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("group %s: %w", fullPath, err))
+			} else if _, dup := seen[strings.ToLower(fullPath)]; dup {
+				errs = append(errs, fmt.Errorf("group %s is declared more than once", fullPath))
+			} else {
+				seen[strings.ToLower(fullPath)] = struct{}{}
+				groups = append(groups, desired)
+			}
+			walk(fullPath, g.Subgroups)
+		}
+	}
+	walk("", c.Groups)
+
+	for _, p := range c.Projects {
+		fullPath := strings.Trim(p.Path, "/")
+		desired, err := resolveProject(fullPath, p)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("project %s: %w", fullPath, err))
+			continue
+		}
+		if _, dup := seen[strings.ToLower(fullPath)]; dup {
+			errs = append(errs, fmt.Errorf("project %s is declared more than once or collides with a group", fullPath))
+			continue
+		}
+		seen[strings.ToLower(fullPath)] = struct{}{}
+		projects = append(projects, desired)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, nil, err
+	}
+	return groups, projects, nil
+}
+
+func resolveGroup(parent, fullPath string, g Group) (desiredGroup, error) {
+	if g.Path == "" || (parent != "" && strings.Contains(strings.Trim(g.Path, "/"), "/")) {
+		return desiredGroup{}, fmt.Errorf("path must be set, subgroup path must not contain '/'")
+	}
+
+	desired := desiredGroup{
+		fullPath:      fullPath,
+		parent:        path.Dir(fullPath),
+		path:          path.Base(fullPath),
+		name:          g.Name,
+		description:   g.Description,
+		visibility:    g.Visibility,
+		manageMembers: g.Members != nil,
+		manageShares:  g.SharedWith != nil,
+	}
+	if desired.parent == "." {
+		desired.parent = ""
+	}
+
+	var errs []error
+	if err := checkVisibility(g.Visibility); err != nil {
+		errs = append(errs, err)
+	}
+	var err error
+	if desired.members, err = resolveMembers(g.Members); err != nil {
+		errs = append(errs, err)
+	}
+	if desired.shares, err = resolveShares(g.SharedWith); err != nil {
+		errs = append(errs, err)
+	}
+	return desired, errors.Join(errs...)
+}
+
+func resolveProject(fullPath string, p Project) (desiredProject, error) {
+	if !strings.Contains(fullPath, "/") {
+		return desiredProject{}, fmt.Errorf("path must be the full path including the namespace")
+	}
+
+	desired := desiredProject{
+		fullPath:      fullPath,
+		namespace:     path.Dir(fullPath),
+		path:          path.Base(fullPath),
+		name:          p.Name,
+		description:   p.Description,
+		visibility:    p.Visibility,
+		settings:      p.Settings,
+		manageMembers: p.Members != nil,
+		manageShares:  p.SharedWith != nil,
+	}
+
+	var errs []error
+	if err := checkVisibility(p.Visibility); err != nil {
+		errs = append(errs, err)
+	}
+	if err := p.Settings.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	var err error
+	if desired.members, err = resolveMembers(p.Members); err != nil {
+		errs = append(errs, err)
+	}
+	if desired.shares, err = resolveShares(p.SharedWith); err != nil {
+		errs = append(errs, err)
+	}
+	return desired, errors.Join(errs...)
+}
+
+func checkVisibility(visibility string) error {
+	switch gitlab.VisibilityValue(visibility) {
+	case "", gitlab.PrivateVisibility, gitlab.InternalVisibility, gitlab.PublicVisibility:
+		return nil
+	}
+	return fmt.Errorf("invalid visibility %q (supported: private, internal, public)", visibility)
+}
+
+func resolveMembers(members []Member) ([]common.Member, error) {
+	var out []common.Member
+	var errs []error
+	for _, m := range members {
+		if m.Username == "" {
+			errs = append(errs, fmt.Errorf("member without username"))
+			continue
+		}
+		level, err := gitlabapi.ParseAccessLevel(m.AccessLevel)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("member %s: %w", m.Username, err))
+			continue
+		}
+		expiresAt, err := common.ParseDate(m.ExpiresAt)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("member %s: %w", m.Username, err))
+			continue
+		}
+		out = append(out, common.Member{Name: m.Username, AccessLevel: level, ExpiresAt: expiresAt, Source: "config"})
+	}
+	return out, errors.Join(errs...)
+}
+
+func resolveShares(shares []Share) ([]desiredShare, error) {
+	var out []desiredShare
+	var errs []error
+	for _, s := range shares {
+		if s.Group == "" {
+			errs = append(errs, fmt.Errorf("share without group"))
+			continue
+		}
+		level, err := gitlabapi.ParseAccessLevel(s.AccessLevel)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("share with %s: %w", s.Group, err))
+			continue
+		}
+		expiresAt, err := common.ParseDate(s.ExpiresAt)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("share with %s: %w", s.Group, err))
+			continue
+		}
+		out = append(out, desiredShare{group: strings.Trim(s.Group, "/"), accessLevel: level, expiresAt: expiresAt})
+	}
+	return out, errors.Join(errs...)
+}
@@ -0,0 +1,131 @@
+package declarative
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	config := &Config{
+		Groups: []Group{{
+			Path:    "/platform/",
+			Members: []Member{},
+			Subgroups: []Group{
+				{Path: "backend", Subgroups: []Group{{Path: "api", SharedWith: []Share{{Group: "ops", AccessLevel: "reporter"}}}}},
+			},
+		}},
+		Projects: []Project{{Path: "platform/backend/service", Members: []Member{{Username: "alice", AccessLevel: "developer", ExpiresAt: "2026-12-31"}}}},
+	}
+
+	groups, projects, err := config.resolve()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		fullPath, parent, path      string
+		manageMembers, manageShares bool
+	}{
+		{"platform", "", "platform", true, false},
+		{"platform/backend", "platform", "backend", false, false},
+		{"platform/backend/api", "platform/backend", "api", false, true},
+	}
+	if len(groups) != len(want) {
+		t.Fatalf("got %d groups, want %d", len(groups), len(want))
+	}
+	for i, w := range want {
+		g := groups[i]
+		if g.fullPath != w.fullPath || g.parent != w.parent || g.path != w.path || g.manageMembers != w.manageMembers || g.manageShares != w.manageShares {
+			t.Errorf("group %d: got %+v, want %+v", i, g, w)
+		}
+	}
+
+	if len(projects) != 1 {
+		t.Fatalf("got %d projects, want 1", len(projects))
+	}
+	project := projects[0]
+	if project.namespace != "platform/backend" || project.path != "service" || !project.manageMembers || project.manageShares {
+		t.Errorf("got %+v", project)
+	}
+	if len(project.members) != 1 || project.members[0].ExpiresAt == nil || project.members[0].ExpiresAt.Format("2006-01-02") != "2026-12-31" {
+		t.Errorf("members %+v", project.members)
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr string
+	}{
+		{
+			name:    "duplicate group",
+			config:  Config{Groups: []Group{{Path: "platform"}, {Path: "Platform"}}},
+			wantErr: "group Platform is declared more than once",
+		},
+		{
+			name:    "duplicate subgroup",
+			config:  Config{Groups: []Group{{Path: "platform", Subgroups: []Group{{Path: "api"}}}, {Path: "platform/api"}}},
+			wantErr: "group platform/api is declared more than once",
+		},
+		{
+			name:    "project collides with a group",
+			config:  Config{Groups: []Group{{Path: "platform", Subgroups: []Group{{Path: "api"}}}}, Projects: []Project{{Path: "platform/api"}}},
+			wantErr: "collides with a group",
+		},
+		{
+			name:    "duplicate project",
+			config:  Config{Projects: []Project{{Path: "platform/api"}, {Path: "/platform/api"}}},
+			wantErr: "project platform/api is declared more than once",
+		},
+		{
+			name:    "subgroup path with slash",
+			config:  Config{Groups: []Group{{Path: "platform", Subgroups: []Group{{Path: "backend/api"}}}}},
+			wantErr: "subgroup path must not contain '/'",
+		},
+		{
+			name:    "group without path",
+			config:  Config{Groups: []Group{{Name: "Platform"}}},
+			wantErr: "path must be set",
+		},
+		{
+			name:    "project without namespace",
+			config:  Config{Projects: []Project{{Path: "service"}}},
+			wantErr: "path must be the full path including the namespace",
+		},
+		{
+			name:    "invalid visibility",
+			config:  Config{Groups: []Group{{Path: "platform", Visibility: "secret"}}},
+			wantErr: `invalid visibility "secret"`,
+		},
+		{
+			name:    "invalid access level",
+			config:  Config{Groups: []Group{{Path: "platform", Members: []Member{{Username: "alice", AccessLevel: "superuser"}}}}},
+			wantErr: `member alice: invalid access level "superuser"`,
+		},
+		{
+			name:    "member without username",
+			config:  Config{Projects: []Project{{Path: "platform/api", Members: []Member{{AccessLevel: "developer"}}}}},
+			wantErr: "member without username",
+		},
+		{
+			name:    "invalid date",
+			config:  Config{Groups: []Group{{Path: "platform", SharedWith: []Share{{Group: "ops", AccessLevel: "reporter", ExpiresAt: "31.12.2026"}}}}},
+			wantErr: `share with ops: invalid expiration date "31.12.2026"`,
+		},
+		{
+			name:    "share without group",
+			config:  Config{Projects: []Project{{Path: "platform/api", SharedWith: []Share{{AccessLevel: "reporter"}}}}},
+			wantErr: "share without group",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := tt.config.resolve()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
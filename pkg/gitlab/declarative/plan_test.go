@@ -0,0 +1,107 @@
+package declarative
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	gitlabapi "github.com/Cloud-for-You/devops-cli/pkg/gitlab"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+func TestPlanRequirement(t *testing.T) {
+	plan := &Plan{Actions: []Action{
+		{Type: ActionCreate, Kind: KindGroup, Path: "platform"},
+		{Type: ActionCreate, Kind: KindGroup, Path: "platform/backend"},
+		{Type: ActionCreate, Kind: KindProject, Path: "platform/backend/api"},
+		{Type: ActionAddMember, Kind: KindGroup, Path: "platform", Target: "alice"},
+		{Type: ActionCreate, Kind: KindGroup, Path: "infra/network"},
+		{Type: ActionCreate, Kind: KindGroup, Path: "infra/network/dns"},
+		{Type: ActionCreate, Kind: KindProject, Path: "infra/terraform"},
+		{Type: ActionCreate, Kind: KindProject, Path: "infra/ansible"},
+		{Type: ActionUpdate, Kind: KindGroup, Path: "infra"},
+		{Type: ActionAddMember, Kind: KindGroup, Path: "infra", Target: "bob"},
+		{Type: ActionUpdate, Kind: KindProject, Path: "infra/legacy"},
+		{Type: ActionShare, Kind: KindProject, Path: "infra/legacy", Target: "platform"},
+		{Type: ActionAddMember, Kind: KindProject, Path: "infra/network/dns", Target: "carol"},
+	}}
+
+	got := plan.Requirement([]string{"api"})
+	want := gitlabapi.Requirement{
+		Scopes:           []string{"api"},
+		CreateGroups:     true,
+		OwnerOf:          []string{"infra"},
+		MaintainerOf:     []string{"infra/legacy"},
+		CreateProjectIn:  []string{"infra"},
+		CreateSubgroupIn: []string{"infra"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestNewPlan(t *testing.T) {
+	responses := map[string]string{
+		"/api/v4/user":            `{"id": 2, "username": "Deployer"}`,
+		"/api/v4/groups/platform": `{"id": 1, "full_path": "platform", "name": "platform", "shared_with_groups": [{"group_id": 7, "group_full_path": "ops", "group_access_level": 20}]}`,
+		"/api/v4/groups/platform/members": `[
+			{"id": 1, "username": "root", "access_level": 50},
+			{"id": 2, "username": "deployer", "access_level": 50},
+			{"id": 3, "username": "alice", "access_level": 30, "expires_at": "2026-06-30"},
+			{"id": 4, "username": "eve", "access_level": 20}
+		]`,
+		// Clenove projektu nejsou spravovani, dotaz na ne by skoncil chybou 404
+		"/api/v4/projects/platform%2Flegacy": `{"id": 5, "path_with_namespace": "platform/legacy", "name": "legacy"}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		body, ok := responses[r.URL.EscapedPath()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			body = `{"message": "404 Not found"}`
+		}
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client, err := gitlab.NewClient("token", gitlab.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := &Config{
+		Groups: []Group{{
+			Path: "platform",
+			Members: []Member{
+				{Username: "alice", AccessLevel: "developer", ExpiresAt: "2026-12-31"},
+				{Username: "deployer", AccessLevel: "developer"},
+				{Username: "dave", AccessLevel: "reporter"},
+			},
+			SharedWith: []Share{},
+			Subgroups:  []Group{{Path: "backend"}},
+		}},
+		Projects: []Project{{Path: "platform/legacy"}},
+	}
+
+	plan, err := NewPlan(client, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, action := range plan.Actions {
+		got = append(got, fmt.Sprintf("%s %v", action, action.Details))
+	}
+	want := []string{
+		"+ create group platform/backend []",
+		"~ update member alice of group platform [expiresAt: 2026-06-30 -> 2026-12-31]",
+		"+ add member dave to group platform [accessLevel: reporter]",
+		"- remove member eve from group platform [accessLevel: reporter]",
+		"- unshare group platform from group ops [accessLevel: reporter]",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
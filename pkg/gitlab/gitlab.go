@@ -2,10 +2,43 @@ package gitlab
 
 import (
 	"fmt"
+	"log/slog"
+	"time"
 
 	gitlab "gitlab.com/gitlab-org/api/client-go"
 )
 
+// Identity describes the authenticated user, the GitLab instance and the token in use
+type Identity struct {
+	UserID   int    `json:"userId"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	State    string `json:"state"`
+	IsAdmin  bool   `json:"isAdmin"`
+	Bot      bool   `json:"bot"`
+
+	CanCreateGroup   bool `json:"canCreateGroup"`
+	CanCreateProject bool `json:"canCreateProject"`
+
+	InstanceURL string `json:"instanceUrl"`
+	Version     string `json:"version,omitempty"`
+	Revision    string `json:"revision,omitempty"`
+
+	// Token is nil for OAuth2 and job tokens, they have no self endpoint
+	Token *TokenInfo `json:"token,omitempty"`
+}
+
+// TokenInfo describes a personal, group or project access token
+type TokenInfo struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	Active     bool       `json:"active"`
+	CreatedAt  *time.Time `json:"createdAt,omitempty"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+}
+
 func Whoami(client *gitlab.Client) (*string, error) {
 	user, _, err := client.Users.CurrentUser()
 	if err != nil {
@@ -13,3 +46,74 @@ func Whoami(client *gitlab.Client) (*string, error) {
 	}
 	return &user.Username, nil
 }
+
+// GetIdentity returns the current user with instance version and token details.
+// Only the current user is required, version and token are filled in when the token may read them.
+func GetIdentity(client *gitlab.Client) (*Identity, error) {
+	user, _, err := client.Users.CurrentUser()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving current user: %w", err)
+	}
+
+	identity := &Identity{
+		UserID:           user.ID,
+		Username:         user.Username,
+		Name:             user.Name,
+		State:            user.State,
+		IsAdmin:          user.IsAdmin,
+		Bot:              user.Bot,
+		CanCreateGroup:   user.CanCreateGroup,
+		CanCreateProject: user.CanCreateProject,
+		InstanceURL:      client.BaseURL().String(),
+	}
+
+	if version, _, err := client.Version.GetVersion(); err == nil {
+		identity.Version = version.Version
+		identity.Revision = version.Revision
+	} else {
+		slog.Debug("cannot retrieve GitLab version", "error", err)
+	}
+
+	// Endpoint /personal_access_tokens/self funguje pro osobni, skupinove i projektove tokeny
+	if token, _, err := client.PersonalAccessTokens.GetSinglePersonalAccessToken(); err == nil {
+		identity.Token = &TokenInfo{
+			ID:         token.ID,
+			Name:       token.Name,
+			Scopes:     token.Scopes,
+			Active:     token.Active,
+			CreatedAt:  token.CreatedAt,
+			ExpiresAt:  (*time.Time)(token.ExpiresAt),
+			LastUsedAt: token.LastUsedAt,
+		}
+	} else {
+		slog.Debug("cannot retrieve token details", "error", err)
+	}
+
+	return identity, nil
+}
+
+// Scopes, ktere zahrnuji jine scopes
+var impliedScopes = map[string][]string{
+	"api":              {"read_api", "read_user", "read_repository", "write_repository"},
+	"read_api":         {"read_user", "read_repository"},
+	"write_repository": {"read_repository"},
+}
+
+// MissingScopes returns the required scopes not granted by the token scopes
+func (t *TokenInfo) MissingScopes(required []string) []string {
+	granted := map[string]bool{}
+	for _, scope := range t.Scopes {
+		granted[scope] = true
+		for _, implied := range impliedScopes[scope] {
+			granted[implied] = true
+		}
+	}
+
+	var missing []string
+	for _, scope := range required {
+		if !granted[scope] {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
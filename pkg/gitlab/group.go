@@ -1,12 +1,21 @@
 package gitlab
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/Cloud-for-You/devops-cli/pkg/audit"
 	gitlab "gitlab.com/gitlab-org/api/client-go"
 )
 
+// ErrUserNotFound is returned when a username does not exist in GitLab,
+// typically a directory user who never signed in
+var ErrUserNotFound = errors.New("user not found in GitLab")
+
 func ListGroups(client *gitlab.Client) ([]*gitlab.Group, error) {
 	var allGroups []*gitlab.Group
 	page := 1
@@ -67,6 +76,28 @@ func ListGitlabGroupMembers(client *gitlab.Client, groupId string) ([]*gitlab.Gr
 	return allMembers, nil
 }
 
+// ListAllGroupMembers returns members of the group including members inherited
+// from parent groups
+func ListAllGroupMembers(client *gitlab.Client, groupID string) ([]*gitlab.GroupMember, error) {
+	var allMembers []*gitlab.GroupMember
+	options := &gitlab.ListGroupMembersOptions{ListOptions: gitlab.ListOptions{Page: 1, PerPage: 100}}
+
+	for {
+		members, res, err := client.Groups.ListAllGroupMembers(groupID, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve group members: %w", err)
+		}
+		allMembers = append(allMembers, members...)
+
+		if res.CurrentPage >= res.TotalPages {
+			break
+		}
+		options.Page++
+	}
+
+	return allMembers, nil
+}
+
 func GetGroup(client *gitlab.Client, groupName string) (*gitlab.Group, error) {
 	group, _, err := client.Groups.GetGroup(groupName, nil)
 	if err != nil {
@@ -84,7 +115,27 @@ func CreateGroup(client *gitlab.Client, groupName string, groupDescription strin
 		Visibility:  gitlab.Ptr(gitlab.VisibilityValue(visibility)),
 	}
 
+	return CreateGroupWithOptions(client, groupOptions)
+}
+
+// CreateGroupWithOptions creates a group, ParentID in the options creates a subgroup
+func CreateGroupWithOptions(client *gitlab.Client, groupOptions *gitlab.CreateGroupOptions) (*gitlab.Group, *gitlab.Response, error) {
 	group, res, err := client.Groups.CreateGroup(groupOptions)
+
+	event := audit.Event{
+		Action: "group.create",
+		Target: audit.Target{Type: "group"},
+	}
+	if groupOptions.Name != nil {
+		event.Target.Name = *groupOptions.Name
+	}
+	if group != nil {
+		event.Target.ID = group.ID
+		event.Target.Name = group.FullPath
+		event.After = groupState(group)
+	}
+	recordAudit(client, event, err)
+
 	if err != nil {
 		return nil, res, err
 	}
@@ -92,129 +143,245 @@ func CreateGroup(client *gitlab.Client, groupName string, groupDescription strin
 	return group, res, nil
 }
 
-// AddUserToGroup adds a user to a group
-func AddMemberToGroup(client *gitlab.Client, groupname string, username string, accessLevel *gitlab.AccessLevelValue) error {
-	// V pripade, ze nepredavame accessLevel, vyresime jeho nastaveni pres jmeno skupiny, ktera musi jmeno obsahovat
-	// Podporovane retezce pro role
-	// Developer -> 30
-	// Maintainer -> 40
-	// Pokud neni ve skupine match, nebudeme ji synchronizovat a vypiseme chybu
-	if accessLevel == nil {
-		lowerGroupName := strings.ToLower(groupname)
-		switch {
-		case strings.Contains(lowerGroupName, "maintainer"):
-			defaultLevel := gitlab.MaintainerPermissions
-			accessLevel = &defaultLevel
-		case strings.Contains(lowerGroupName, "developer"):
-			defaultLevel := gitlab.DeveloperPermissions
-			accessLevel = &defaultLevel
-		default:
-			return fmt.Errorf("unsupported role in groupname: %s", groupname)
-		}
-	}
-
-	// Na zaklade jmena skupiny ziskame jeji ID
-	groups, _, err := client.Groups.ListGroups(&gitlab.ListGroupsOptions{
-		Search: &groupname,
-	})
-	if err != nil {
-		return fmt.Errorf("error retrieving group: %w", err)
+// UpdateGroup changes settings of the group given by ID or full path
+func UpdateGroup(client *gitlab.Client, groupID string, options *gitlab.UpdateGroupOptions) (*gitlab.Group, error) {
+	var before *objectState
+	if audit.Enabled() {
+		if current, err := GetGroup(client, groupID); err == nil {
+			before = groupState(current)
+		}
 	}
 
-	var groupID int
-	for _, group := range groups {
-		if group.Name == groupname {
-			groupID = group.ID
-			break
-		}
+	group, _, err := client.Groups.UpdateGroup(groupID, options)
+
+	event := audit.Event{
+		Action: "group.update",
+		Target: audit.Target{Type: "group", Name: groupID},
+		Before: before,
 	}
+	if group != nil {
+		event.Target.ID = group.ID
+		event.Target.Name = group.FullPath
+		event.After = groupState(group)
+	}
+	recordAudit(client, event, err)
 
-	if groupID == 0 {
-		return fmt.Errorf("group '%s' not found in GitLab", groupname)
+	if err != nil {
+		return nil, fmt.Errorf("error updating group '%s': %w", groupID, err)
 	}
 
-	// Na zaklade jmena uzivatele ziskame jeho ID
+	slog.Debug("group updated", "group", group.FullPath)
+	return group, nil
+}
+
+// findUserID returns the ID of the user with exactly the given username
+func findUserID(client *gitlab.Client, username string) (int, error) {
 	users, _, err := client.Users.ListUsers(&gitlab.ListUsersOptions{
 		Username: &username,
 	})
 	if err != nil {
-		return fmt.Errorf("error retrieving user: %w", err)
+		return 0, fmt.Errorf("error retrieving user: %w", err)
 	}
 
-	var userID int
 	for _, user := range users {
 		if user.Username == username {
-			userID = user.ID
-			break
+			return user.ID, nil
 		}
 	}
 
-	if userID == 0 {
-		fmt.Printf("user '%s' not found in GitLab\n", username)
-		return nil
+	return 0, fmt.Errorf("user '%s': %w", username, ErrUserNotFound)
+}
+
+// DeleteGroup deletes the group given by ID or full path. With delayed deletion
+// enabled the group is only marked for deletion and the date of the removal is
+// returned, permanently removes a group which is already marked.
+func DeleteGroup(client *gitlab.Client, groupID string, permanently bool) (*time.Time, error) {
+	group, err := GetGroup(client, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving group '%s': %w", groupID, err)
 	}
 
-	// Pridame uzivatele do skupiny
-	_, _, err = client.GroupMembers.AddGroupMember(groupID, &gitlab.AddGroupMemberOptions{
-		UserID:      &userID,
-		AccessLevel: accessLevel,
-	})
+	options := &gitlab.DeleteGroupOptions{}
+	if permanently {
+		options.PermanentlyRemove = gitlab.Ptr(true)
+		options.FullPath = gitlab.Ptr(group.FullPath)
+	}
+	_, err = client.Groups.DeleteGroup(group.ID, options)
+
+	recordAudit(client, audit.Event{
+		Action: "group.delete",
+		Target: audit.Target{Type: "group", ID: group.ID, Name: group.FullPath},
+		Before: groupState(group),
+	}, err)
+
 	if err != nil {
-		return fmt.Errorf("error adding user to group: %w", err)
+		return nil, fmt.Errorf("error deleting group '%s': %w", group.FullPath, err)
 	}
 
-	fmt.Printf("User '%s' successfully added to the group '%s'.\n", username, groupname)
-	return nil
+	// Skupina oznacena ke smazani je dal dostupna
+	if deleted, err := GetGroup(client, strconv.Itoa(group.ID)); err == nil && deleted.MarkedForDeletionOn != nil {
+		return (*time.Time)(deleted.MarkedForDeletionOn), nil
+	}
+	return nil, nil
 }
 
-// RemoveUserFromGroup removes a user from a group
-func RemoveUserFromGroup(client *gitlab.Client, groupname string, username string) error {
-	// Retrieve group ID by name
-	groups, _, err := client.Groups.ListGroups(&gitlab.ListGroupsOptions{
-		Search: &groupname,
-	})
+// RestoreGroup restores a group marked for deletion
+func RestoreGroup(client *gitlab.Client, groupID string) (*gitlab.Group, error) {
+	group, _, err := client.Groups.RestoreGroup(groupID)
+
+	event := audit.Event{
+		Action: "group.restore",
+		Target: audit.Target{Type: "group", Name: groupID},
+	}
+	if group != nil {
+		event.Target.ID = group.ID
+		event.Target.Name = group.FullPath
+		event.After = groupState(group)
+	}
+	recordAudit(client, event, err)
+
 	if err != nil {
-		return fmt.Errorf("error retrieving group: %w", err)
+		return nil, fmt.Errorf("error restoring group '%s': %w", groupID, err)
 	}
+	return group, nil
+}
 
-	var groupID int
-	for _, group := range groups {
-		if group.Name == groupname {
-			groupID = group.ID
-			break
+// TransferGroup moves the group under a new parent group, an empty parent
+// turns the group into a top-level group
+func TransferGroup(client *gitlab.Client, groupID string, parent string) (*gitlab.Group, error) {
+	group, err := GetGroup(client, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving group '%s': %w", groupID, err)
+	}
+
+	options := &gitlab.TransferSubGroupOptions{}
+	if parent != "" {
+		target, err := GetGroup(client, parent)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving parent group '%s': %w", parent, err)
 		}
+		options.GroupID = gitlab.Ptr(target.ID)
 	}
+	transferred, _, err := client.Groups.TransferSubGroup(group.ID, options)
 
-	if groupID == 0 {
-		return fmt.Errorf("group '%s' not found", groupname)
+	event := audit.Event{
+		Action: "group.transfer",
+		Target: audit.Target{Type: "group", ID: group.ID, Name: group.FullPath},
+		Before: groupState(group),
 	}
+	if transferred != nil {
+		event.After = groupState(transferred)
+	}
+	recordAudit(client, event, err)
 
-	// Retrieve user ID by username
-	users, _, err := client.Users.ListUsers(&gitlab.ListUsersOptions{
-		Username: &username,
-	})
 	if err != nil {
-		return fmt.Errorf("error retrieving user: %w", err)
+		return nil, fmt.Errorf("error transferring group '%s': %w", group.FullPath, err)
 	}
+	return transferred, nil
+}
 
-	var userID int
-	for _, user := range users {
-		if user.Username == username {
-			userID = user.ID
-			break
-		}
+// branchProtections are the presets of the default branch protection of new projects
+var branchProtections = map[string]struct {
+	push, merge        gitlab.AccessLevelValue
+	forcePush, initial bool
+}{
+	"none":           {push: gitlab.DeveloperPermissions, merge: gitlab.DeveloperPermissions, forcePush: true},
+	"partial":        {push: gitlab.DeveloperPermissions, merge: gitlab.DeveloperPermissions},
+	"push-protected": {push: gitlab.MaintainerPermissions, merge: gitlab.DeveloperPermissions},
+	"full":           {push: gitlab.MaintainerPermissions, merge: gitlab.MaintainerPermissions},
+	"initial-push":   {push: gitlab.MaintainerPermissions, merge: gitlab.MaintainerPermissions, initial: true},
+}
+
+// BranchProtectionOptions returns the default branch protection preset: none (developers
+// push and force push), partial (developers push), push-protected (developers merge),
+// full (maintainers only) or initial-push (full, developers push the initial commit)
+func BranchProtectionOptions(preset string) (*gitlab.DefaultBranchProtectionDefaultsOptions, error) {
+	p, ok := branchProtections[preset]
+	if !ok {
+		return nil, fmt.Errorf("invalid default branch protection %q (supported: none, partial, push-protected, full, initial-push)", preset)
 	}
+	return &gitlab.DefaultBranchProtectionDefaultsOptions{
+		AllowedToPush:           &[]*gitlab.GroupAccessLevel{{AccessLevel: gitlab.Ptr(p.push)}},
+		AllowedToMerge:          &[]*gitlab.GroupAccessLevel{{AccessLevel: gitlab.Ptr(p.merge)}},
+		AllowForcePush:          gitlab.Ptr(p.forcePush),
+		DeveloperCanInitialPush: gitlab.Ptr(p.initial),
+	}, nil
+}
 
-	if userID == 0 {
-		return fmt.Errorf("user '%s' not found", username)
+// BranchProtectionName returns the preset matching the group defaults, "custom" when none matches
+func BranchProtectionName(defaults *gitlab.BranchProtectionDefaults) string {
+	if defaults == nil {
+		return ""
+	}
+	level := func(levels []*gitlab.GroupAccessLevel) gitlab.AccessLevelValue {
+		// Rozhoduje nejnizsi uroven, ktera smi akci provest
+		lowest := gitlab.NoPermissions
+		for _, l := range levels {
+			if l.AccessLevel != nil && (lowest == gitlab.NoPermissions || *l.AccessLevel < lowest) {
+				lowest = *l.AccessLevel
+			}
+		}
+		return lowest
+	}
+	push, merge := level(defaults.AllowedToPush), level(defaults.AllowedToMerge)
+	for name, p := range branchProtections {
+		if p.push == push && p.merge == merge && p.forcePush == defaults.AllowForcePush && p.initial == defaults.DeveloperCanInitialPush {
+			return name
+		}
 	}
+	return "custom"
+}
 
-	// Remove user from group
-	_, err = client.GroupMembers.RemoveGroupMember(groupID, userID, nil)
-	if err != nil {
-		return fmt.Errorf("error removing user from group: %w", err)
+// EnsureGroupPath returns the group with the full path. Missing groups on the
+// path are created like "mkdir -p", the name of a created group is its path.
+func EnsureGroupPath(client *gitlab.Client, fullPath string, visibility string) (*gitlab.Group, error) {
+	var parent *gitlab.Group
+	for _, segment := range strings.Split(strings.Trim(fullPath, "/"), "/") {
+		path := segment
+		if parent != nil {
+			path = parent.FullPath + "/" + segment
+		}
+
+		group, err := GetGroup(client, path)
+		if err == nil {
+			parent = group
+			continue
+		}
+		if !IsNotFound(err) {
+			return nil, fmt.Errorf("error retrieving group '%s': %w", path, err)
+		}
+
+		options := &gitlab.CreateGroupOptions{
+			Name:       gitlab.Ptr(segment),
+			Path:       gitlab.Ptr(segment),
+			Visibility: gitlab.Ptr(gitlab.VisibilityValue(visibility)),
+		}
+		if parent != nil {
+			options.ParentID = gitlab.Ptr(parent.ID)
+		}
+		group, _, err = CreateGroupWithOptions(client, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GitLab group '%s': %w", path, err)
+		}
+		slog.Info("GitLab group created", "group", group.FullPath)
+		parent = group
 	}
+	return parent, nil
+}
 
-	fmt.Printf("User '%s' successfully remove from group '%s'.\n", username, groupname)
-	return nil
+// ExistingGroupAncestor returns the full path of the deepest existing group on
+// the full path, empty string when not even the top-level group exists.
+func ExistingGroupAncestor(client *gitlab.Client, fullPath string) (string, error) {
+	segments := strings.Split(strings.Trim(fullPath, "/"), "/")
+	for i := len(segments); i > 0; i-- {
+		path := strings.Join(segments[:i], "/")
+		_, err := GetGroup(client, path)
+		if err == nil {
+			return path, nil
+		}
+		if !IsNotFound(err) {
+			return "", fmt.Errorf("error retrieving group '%s': %w", path, err)
+		}
+	}
+	return "", nil
 }
@@ -0,0 +1,353 @@
+package daemon
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	common "github.com/Cloud-for-You/devops-cli/pkg"
+	"github.com/Cloud-for-You/devops-cli/pkg/gitlab/groupsync"
+)
+
+// RunFunc performs one synchronization and returns its plan and result
+type RunFunc func() (*groupsync.Plan, *groupsync.Result, error)
+
+// Job is a named synchronization executed on a schedule. Schedule is a cron
+// expression (5 fields) or a descriptor like "@hourly" or "@every 30m".
+type Job struct {
+	Name     string
+	Schedule string
+	Run      RunFunc
+}
+
+// Status is the state of a job kept in memory between runs. The last plan and
+// result do not contain e-mails and external IDs (LDAP DNs) of members.
+type Status struct {
+	Name       string            `json:"name"`
+	Schedule   string            `json:"schedule"`
+	Running    bool              `json:"running"`
+	Runs       int               `json:"runs"`
+	Skipped    int               `json:"skipped"`
+	LastStart  *time.Time        `json:"lastStart,omitempty"`
+	LastFinish *time.Time        `json:"lastFinish,omitempty"`
+	NextRun    *time.Time        `json:"nextRun,omitempty"`
+	LastError  string            `json:"lastError,omitempty"`
+	LastPlan   *groupsync.Plan   `json:"lastPlan,omitempty"`
+	LastResult *groupsync.Result `json:"lastResult,omitempty"`
+}
+
+// Healthy reports whether the last finished run succeeded
+func (s Status) Healthy() bool {
+	return s.LastError == "" && (s.LastResult == nil || !s.LastResult.Failed())
+}
+
+type job struct {
+	Job
+	entryID cron.EntryID
+	// lock zabranuje prekryvajicim se behum stejne synchronizace
+	lock   sync.Mutex
+	mu     sync.RWMutex
+	status Status
+}
+
+// Daemon runs jobs on their schedules and keeps their last state
+type Daemon struct {
+	cron *cron.Cron
+	mu   sync.RWMutex
+	jobs map[string]*job
+	// runs are runs started outside of the scheduler (runOnStart, Trigger)
+	runs     sync.WaitGroup
+	stopping bool
+}
+
+func New() *Daemon {
+	return &Daemon{
+		cron: cron.New(),
+		jobs: make(map[string]*job),
+	}
+}
+
+// Add registers a job, names must be unique
+func (d *Daemon) Add(j Job) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if j.Name == "" {
+		return fmt.Errorf("sync name must not be empty")
+	}
+	if _, exists := d.jobs[j.Name]; exists {
+		return fmt.Errorf("sync '%s' is defined more than once", j.Name)
+	}
+
+	entry := &job{Job: j, status: Status{Name: j.Name, Schedule: j.Schedule}}
+	id, err := d.cron.AddFunc(j.Schedule, func() { d.run(entry) })
+	if err != nil {
+		return fmt.Errorf("invalid schedule of sync '%s': %w", j.Name, err)
+	}
+	entry.entryID = id
+	d.jobs[j.Name] = entry
+
+	return nil
+}
+
+// Start starts the scheduler, when runNow is set every job is executed immediately
+func (d *Daemon) Start(runNow bool) {
+	d.cron.Start()
+	if runNow {
+		d.mu.RLock()
+		defer d.mu.RUnlock()
+		for _, j := range d.jobs {
+			d.runNow(j)
+		}
+	}
+}
+
+// Stop stops the scheduler and waits until all running jobs, scheduled and
+// triggered, finish or ctx is done
+func (d *Daemon) Stop(ctx context.Context) {
+	d.mu.Lock()
+	d.stopping = true
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		<-d.cron.Stop().Done()
+		d.runs.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
+// Trigger runs the job immediately, it returns false when the job does not exist
+func (d *Daemon) Trigger(name string) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	j, ok := d.jobs[name]
+	if ok {
+		d.runNow(j)
+	}
+	return ok
+}
+
+// runNow starts the job outside of the scheduler, d.mu must be held
+func (d *Daemon) runNow(j *job) {
+	// Po Stop uz nove behy nespoustime, Stop na ne nepocka
+	if d.stopping {
+		slog.Warn("daemon is stopping, run is not started", "sync", j.Name)
+		return
+	}
+	d.runs.Add(1)
+	go func() {
+		defer d.runs.Done()
+		d.run(j)
+	}()
+}
+
+func (d *Daemon) run(j *job) {
+	// Predchozi beh jeste nedobehl, tento preskocime
+	if !j.lock.TryLock() {
+		slog.Warn("previous run is still in progress, skipping", "sync", j.Name)
+		j.mu.Lock()
+		j.status.Skipped++
+		j.mu.Unlock()
+		return
+	}
+	defer j.lock.Unlock()
+
+	start := time.Now()
+	j.mu.Lock()
+	j.status.Running = true
+	j.status.LastStart = &start
+	j.mu.Unlock()
+
+	plan, result, err := j.Run()
+
+	finish := time.Now()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.status.Running = false
+	j.status.Runs++
+	j.status.LastFinish = &finish
+	j.status.LastPlan = redactPlan(plan)
+	j.status.LastResult = redactResult(result)
+	j.status.LastError = ""
+	if err != nil {
+		j.status.LastError = err.Error()
+	}
+}
+
+// Status returns state of all jobs sorted by name
+func (d *Daemon) Status() []Status {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	statuses := make([]Status, 0, len(d.jobs))
+	for _, j := range d.jobs {
+		statuses = append(statuses, d.jobStatus(j))
+	}
+	sort.Slice(statuses, func(a, b int) bool { return statuses[a].Name < statuses[b].Name })
+	return statuses
+}
+
+func (d *Daemon) jobStatus(j *job) Status {
+	j.mu.RLock()
+	status := j.status
+	j.mu.RUnlock()
+
+	if next := d.cron.Entry(j.entryID).Next; !next.IsZero() {
+		status.NextRun = &next
+	}
+	return status
+}
+
+// Handler exposes the daemon state over HTTP:
+//
+//	GET  /healthz             liveness, always 200 while the process serves requests
+//	GET  /status              state of all syncs including the last plan and result
+//	GET  /status/{name}       state of one sync, 503 when its last run failed
+//	POST /run/{name}          trigger the sync immediately, requires "Authorization: Bearer <runToken>"
+//
+// Without runToken triggering over HTTP is disabled.
+func (d *Daemon) Handler(runToken string) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, d.Status())
+	})
+
+	mux.HandleFunc("GET /status/{name}", func(w http.ResponseWriter, r *http.Request) {
+		d.mu.RLock()
+		j, ok := d.jobs[r.PathValue("name")]
+		d.mu.RUnlock()
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		status := d.jobStatus(j)
+		code := http.StatusOK
+		if !status.Healthy() {
+			code = http.StatusServiceUnavailable
+		}
+		writeJSON(w, code, status)
+	})
+
+	mux.HandleFunc("POST /run/{name}", func(w http.ResponseWriter, r *http.Request) {
+		if runToken == "" {
+			http.Error(w, "triggering over HTTP is disabled, configure runToken", http.StatusForbidden)
+			return
+		}
+		if !authorized(r, runToken) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "invalid or missing bearer token", http.StatusUnauthorized)
+			return
+		}
+		if !d.Trigger(r.PathValue("name")) {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
+
+// authorized compares the bearer token of the request in constant time
+func authorized(r *http.Request, token string) bool {
+	given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
+
+// redactPlan returns a copy of the plan without personal data of members
+func redactPlan(plan *groupsync.Plan) *groupsync.Plan {
+	if plan == nil {
+		return nil
+	}
+	redacted := *plan
+	redacted.Groups = make([]groupsync.GroupPlan, len(plan.Groups))
+	for i, group := range plan.Groups {
+		group.Changes = redactChanges(group.Changes)
+		redacted.Groups[i] = group
+	}
+	return &redacted
+}
+
+// redactResult returns a copy of the result without personal data of members
+func redactResult(result *groupsync.Result) *groupsync.Result {
+	if result == nil {
+		return nil
+	}
+	redacted := *result
+	redacted.Groups = make([]groupsync.GroupResult, len(result.Groups))
+	for i, group := range result.Groups {
+		group.Applied = redactChanges(group.Applied)
+		group.Failed = redactFailed(group.Failed)
+		group.Skipped = redactFailed(group.Skipped)
+		redacted.Groups[i] = group
+	}
+	return &redacted
+}
+
+func redactFailed(failed []groupsync.FailedChange) []groupsync.FailedChange {
+	if failed == nil {
+		return nil
+	}
+	redacted := make([]groupsync.FailedChange, len(failed))
+	for i, f := range failed {
+		f.Change = redactChange(f.Change)
+		redacted[i] = f
+	}
+	return redacted
+}
+
+func redactChanges(changes common.Changeset) common.Changeset {
+	if changes == nil {
+		return nil
+	}
+	redacted := make(common.Changeset, len(changes))
+	for i, change := range changes {
+		redacted[i] = redactChange(change)
+	}
+	return redacted
+}
+
+// redactChange keeps only the username of the member, e-mails and DNs stay in the process
+func redactChange(change common.MemberChange) common.MemberChange {
+	change.Member = redactMember(change.Member)
+	if change.Current != nil {
+		current := redactMember(*change.Current)
+		change.Current = &current
+	}
+	return change
+}
+
+func redactMember(m common.Member) common.Member {
+	m.Email = ""
+	m.ExternalID = ""
+	return m
+}
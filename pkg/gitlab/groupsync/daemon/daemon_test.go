@@ -0,0 +1,128 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	common "github.com/Cloud-for-You/devops-cli/pkg"
+	"github.com/Cloud-for-You/devops-cli/pkg/gitlab/groupsync"
+)
+
+func newTestDaemon(t *testing.T, run RunFunc) *Daemon {
+	t.Helper()
+	d := New()
+	if err := d.Add(Job{Name: "corporate", Schedule: "@every 1h", Run: run}); err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestRunRequiresToken(t *testing.T) {
+	var runs atomic.Int32
+	d := newTestDaemon(t, func() (*groupsync.Plan, *groupsync.Result, error) {
+		runs.Add(1)
+		return nil, nil, nil
+	})
+
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		want          int
+	}{
+		{name: "disabled without token", authorization: "Bearer secret", want: http.StatusForbidden},
+		{name: "missing header", token: "secret", want: http.StatusUnauthorized},
+		{name: "wrong token", token: "secret", authorization: "Bearer wrong", want: http.StatusUnauthorized},
+		{name: "not bearer", token: "secret", authorization: "secret", want: http.StatusUnauthorized},
+		{name: "valid token", token: "secret", authorization: "Bearer secret", want: http.StatusAccepted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/run/corporate", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			d.Handler(tt.token).ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+
+	d.Stop(context.Background())
+	if got := runs.Load(); got != 1 {
+		t.Errorf("%d runs, want 1", got)
+	}
+}
+
+func TestStopWaitsForTriggeredRuns(t *testing.T) {
+	var finished atomic.Bool
+	d := newTestDaemon(t, func() (*groupsync.Plan, *groupsync.Result, error) {
+		time.Sleep(100 * time.Millisecond)
+		finished.Store(true)
+		return nil, nil, nil
+	})
+	d.Start(false)
+
+	if !d.Trigger("corporate") {
+		t.Fatal("sync not found")
+	}
+	d.Stop(context.Background())
+	if !finished.Load() {
+		t.Error("Stop returned before the triggered run finished")
+	}
+
+	// Po Stop se dalsi beh nespusti
+	d.Trigger("corporate")
+	time.Sleep(50 * time.Millisecond)
+	if runs := d.Status()[0].Runs; runs != 1 {
+		t.Errorf("%d runs, want 1", runs)
+	}
+}
+
+func TestStatusWithoutPersonalData(t *testing.T) {
+	member := common.Member{Name: "alice", Email: "alice@example.com", ExternalID: "CN=Alice,OU=Users,DC=example,DC=com"}
+	d := newTestDaemon(t, func() (*groupsync.Plan, *groupsync.Result, error) {
+		changes := common.Changeset{
+			{Type: common.ChangeAdd, Member: member},
+			{Type: common.ChangeUpdateAccess, Member: member, Current: &member},
+		}
+		plan := &groupsync.Plan{Source: "ldap", Groups: []groupsync.GroupPlan{{Name: "GL-Backend", Changes: changes}}}
+		result := &groupsync.Result{Groups: []groupsync.GroupResult{{
+			Name:    "GL-Backend",
+			Applied: changes,
+			Failed:  []groupsync.FailedChange{{Change: changes[0], Error: "forbidden"}},
+		}}}
+		return plan, result, nil
+	})
+	d.Start(false)
+	d.Trigger("corporate")
+	d.Stop(context.Background())
+
+	rec := httptest.NewRecorder()
+	d.Handler("").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	body := rec.Body.String()
+	for _, personal := range []string{member.Email, member.ExternalID} {
+		if strings.Contains(body, personal) {
+			t.Errorf("status contains %q", personal)
+		}
+	}
+	if !strings.Contains(body, `"alice"`) {
+		t.Error("status does not contain the username")
+	}
+
+	var statuses []Status
+	if err := json.Unmarshal(rec.Body.Bytes(), &statuses); err != nil {
+		t.Fatal(err)
+	}
+	if statuses[0].Healthy() {
+		t.Error("status with a failed change is healthy")
+	}
+}
@@ -0,0 +1,270 @@
+package groupsync
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	common "github.com/Cloud-for-You/devops-cli/pkg"
+	gitlabapi "github.com/Cloud-for-You/devops-cli/pkg/gitlab"
+	"github.com/Cloud-for-You/devops-cli/pkg/metrics"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// Group is a group read from a sync source (LDAP, Azure, File, etc...) with its desired members
+type Group struct {
+	Name    string
+	Members []common.Member
+	// Shares are set by MapTeams
+	Shares []Share
+}
+
+// GroupPlan is the list of changes needed to synchronize one group
+type GroupPlan struct {
+	Name string `json:"name"`
+	// Create is set when the group does not exist in GitLab yet
+	Create  bool             `json:"create"`
+	Changes common.Changeset `json:"changes"`
+	Shares  []ShareChange    `json:"shares,omitempty"`
+}
+
+// Plan is the computed difference between a sync source and GitLab
+type Plan struct {
+	Source  string      `json:"source"`
+	Created time.Time   `json:"created"`
+	Groups  []GroupPlan `json:"groups"`
+}
+
+// FailedChange is a change that could not be applied
+type FailedChange struct {
+	Change common.MemberChange `json:"change"`
+	Error  string              `json:"error"`
+}
+
+// GroupResult is the outcome of applying a GroupPlan
+type GroupResult struct {
+	Name    string           `json:"name"`
+	Created bool             `json:"created"`
+	Applied common.Changeset `json:"applied"`
+	Failed  []FailedChange   `json:"failed,omitempty"`
+	// Skipped are additions of users who do not exist in GitLab yet
+	Skipped      []FailedChange `json:"skipped,omitempty"`
+	Shared       []ShareChange  `json:"shared,omitempty"`
+	FailedShares []FailedShare  `json:"failedShares,omitempty"`
+	Error        string         `json:"error,omitempty"`
+}
+
+// Result is the outcome of applying a Plan
+type Result struct {
+	Started  time.Time     `json:"started"`
+	Finished time.Time     `json:"finished"`
+	Groups   []GroupResult `json:"groups"`
+}
+
+// Failed reports whether any group or change failed
+func (r *Result) Failed() bool {
+	for _, g := range r.Groups {
+		if g.Error != "" || len(g.Failed) > 0 || len(g.FailedShares) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Empty reports whether the plan contains nothing to do
+func (p *Plan) Empty() bool {
+	for _, g := range p.Groups {
+		if g.Create || !g.Changes.Empty() || len(g.Shares) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Requirement returns permissions needed to apply the plan with a token of the
+// given scopes: create missing groups, be owner of groups whose members change
+// or which are shared into and maintainer of projects which are shared into
+func (p *Plan) Requirement(scopes []string) gitlabapi.Requirement {
+	requirement := gitlabapi.Requirement{Scopes: scopes}
+	for _, g := range p.Groups {
+		switch {
+		case g.Create:
+			requirement.CreateGroups = true
+		case !g.Changes.Empty():
+			requirement.OwnerOf = append(requirement.OwnerOf, g.Name)
+		}
+		for _, share := range g.Shares {
+			switch share.Kind {
+			case ShareKindGroup:
+				requirement.OwnerOf = append(requirement.OwnerOf, share.Target)
+			case ShareKindProject:
+				requirement.MaintainerOf = append(requirement.MaintainerOf, share.Target)
+			}
+		}
+	}
+	return requirement
+}
+
+// NewPlan compares the source groups with GitLab. Members "root" and the user
+// owning the token are never removed, they manage the groups.
+func NewPlan(client *gitlab.Client, source string, groups []Group) (*Plan, error) {
+	whoami, err := gitlabapi.Whoami(client)
+	if err != nil {
+		return nil, err
+	}
+	ignored := map[string]struct{}{"root": {}, *whoami: {}}
+
+	plan := &Plan{Source: source, Created: time.Now()}
+	for _, group := range groups {
+		// Skupina v GitLabu neexistuje, zalozime ji a pridame vsechny cleny
+		if _, err := gitlabapi.GetGroup(client, group.Name); err != nil {
+			if !gitlabapi.IsNotFound(err) {
+				return nil, fmt.Errorf("error retrieving GitLab group '%s': %w", group.Name, err)
+			}
+			shares, _ := planShares(client, group, true)
+			plan.Groups = append(plan.Groups, GroupPlan{
+				Name:    group.Name,
+				Create:  true,
+				Changes: common.CompareMembers(nil, group.Members),
+				Shares:  shares,
+			})
+			continue
+		}
+
+		gitlabMembersRaw, err := gitlabapi.ListGitlabGroupMembers(client, group.Name)
+		if err != nil {
+			return nil, fmt.Errorf("error listing GitLab group members of '%s': %w", group.Name, err)
+		}
+
+		var gitlabMembers []common.Member
+		for _, member := range gitlabMembersRaw {
+			if _, skip := ignored[member.Username]; skip {
+				continue
+			}
+			gitlabMembers = append(gitlabMembers, common.Member{
+				Name:        member.Username,
+				Email:       member.Email,
+				AccessLevel: member.AccessLevel,
+				ExpiresAt:   (*time.Time)(member.ExpiresAt),
+				Source:      "gitlab",
+			})
+		}
+
+		shares, err := planShares(client, group, false)
+		if err != nil {
+			return nil, err
+		}
+		plan.Groups = append(plan.Groups, GroupPlan{
+			Name:    group.Name,
+			Changes: common.CompareMembers(gitlabMembers, group.Members),
+			Shares:  shares,
+		})
+	}
+
+	return plan, nil
+}
+
+// Apply executes the plan. Failures of single changes do not stop the run,
+// they are collected in the result and logged to logger.
+func Apply(client *gitlab.Client, plan *Plan, logger *slog.Logger) *Result {
+	result := &Result{Started: time.Now()}
+
+	for _, groupPlan := range plan.Groups {
+		groupResult := GroupResult{Name: groupPlan.Name}
+
+		if groupPlan.Create {
+			_, response, err := gitlabapi.CreateGroup(client, groupPlan.Name, "", "private")
+			if err != nil && (response == nil || response.StatusCode != http.StatusConflict) {
+				groupResult.Error = fmt.Sprintf("failed to create GitLab group: %v", err)
+				logger.Error("failed to create GitLab group", "group", groupPlan.Name, "error", err)
+				result.Groups = append(result.Groups, groupResult)
+				continue
+			}
+			groupResult.Created = err == nil
+			if groupResult.Created {
+				logger.Info("GitLab group created", "group", groupPlan.Name)
+			}
+		}
+
+		for _, change := range groupPlan.Changes {
+			attrs := []any{"group", groupPlan.Name, "member", change.Member.Name, "change", change.Type}
+
+			err := applyChange(client, groupPlan.Name, change)
+			metrics.ObserveMemberChange(groupPlan.Name, string(change.Type), err)
+			switch {
+			case errors.Is(err, gitlabapi.ErrUserNotFound) && change.Type == common.ChangeAdd:
+				// Uzivatel se do GitLabu jeste neprihlasil, pridame ho v nekterem dalsim behu
+				groupResult.Skipped = append(groupResult.Skipped, FailedChange{Change: change, Error: err.Error()})
+				logger.Warn("member skipped, user does not exist in GitLab", attrs...)
+			case err != nil:
+				groupResult.Failed = append(groupResult.Failed, FailedChange{Change: change, Error: err.Error()})
+				logger.Error("failed to apply member change", append(attrs, "error", err)...)
+			default:
+				groupResult.Applied = append(groupResult.Applied, change)
+				logger.Info("member change applied", attrs...)
+			}
+		}
+
+		// Sdileni az po clenech, aby tym mel pri sdileni aktualni obsazeni
+		for _, share := range groupPlan.Shares {
+			attrs := []any{"group", groupPlan.Name, "kind", share.Kind, "target", share.Target, "accessLevel", gitlabapi.AccessLevelName(share.AccessLevel)}
+			if err := applyShare(client, groupPlan.Name, share); err != nil {
+				groupResult.FailedShares = append(groupResult.FailedShares, FailedShare{Change: share, Error: err.Error()})
+				logger.Error("failed to share team group", append(attrs, "error", err)...)
+				continue
+			}
+			groupResult.Shared = append(groupResult.Shared, share)
+			logger.Info("team group shared", attrs...)
+		}
+
+		result.Groups = append(result.Groups, groupResult)
+	}
+
+	result.Finished = time.Now()
+	return result
+}
+
+func applyChange(client *gitlab.Client, groupName string, change common.MemberChange) error {
+	m := change.Member
+
+	switch change.Type {
+	case common.ChangeAdd:
+		accessLevel := m.AccessLevel
+		if accessLevel == 0 {
+			var err error
+			if accessLevel, err = groupNameAccessLevel(groupName); err != nil {
+				return err
+			}
+		}
+		return gitlabapi.AddGroupMember(client, groupName, m.Name, accessLevel, m.ExpiresAt)
+	case common.ChangeRemove:
+		return gitlabapi.RemoveGroupMember(client, groupName, m.Name)
+	case common.ChangeUpdateAccess:
+		return gitlabapi.EditGroupMember(client, groupName, m.Name, m.AccessLevel, change.Current.ExpiresAt)
+	case common.ChangeUpdateExpiry:
+		return gitlabapi.EditGroupMember(client, groupName, m.Name, change.Current.AccessLevel, m.ExpiresAt)
+	default:
+		return fmt.Errorf("unsupported change type %s", change.Type)
+	}
+}
+
+// groupNameAccessLevel returns the access level of members without an explicit
+// role, the name of the group has to contain it
+func groupNameAccessLevel(groupName string) (gitlab.AccessLevelValue, error) {
+	// Podporovane retezce pro role
+	// Developer -> 30
+	// Maintainer -> 40
+	// Pokud neni ve skupine match, nebudeme ji synchronizovat a vypiseme chybu
+	lowerGroupName := strings.ToLower(groupName)
+	switch {
+	case strings.Contains(lowerGroupName, "maintainer"):
+		return gitlab.MaintainerPermissions, nil
+	case strings.Contains(lowerGroupName, "developer"):
+		return gitlab.DeveloperPermissions, nil
+	default:
+		return 0, fmt.Errorf("unsupported role in groupname: %s", groupName)
+	}
+}
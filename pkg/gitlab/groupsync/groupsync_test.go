@@ -0,0 +1,31 @@
+package groupsync
+
+import (
+	"reflect"
+	"testing"
+
+	common "github.com/Cloud-for-You/devops-cli/pkg"
+	gitlabapi "github.com/Cloud-for-You/devops-cli/pkg/gitlab"
+)
+
+func TestPlanRequirement(t *testing.T) {
+	plan := &Plan{Groups: []GroupPlan{
+		{Name: "GL-New", Create: true},
+		{Name: "GL-Backend", Changes: common.Changeset{{Type: common.ChangeAdd, Member: common.Member{Name: "alice"}}}},
+		{Name: "GL-Unchanged", Shares: []ShareChange{
+			{Type: ShareAdd, Kind: ShareKindGroup, Target: "platform/backend"},
+			{Type: ShareAdd, Kind: ShareKindProject, Target: "platform/legacy"},
+		}},
+	}}
+
+	got := plan.Requirement([]string{"api"})
+	want := gitlabapi.Requirement{
+		Scopes:       []string{"api"},
+		CreateGroups: true,
+		OwnerOf:      []string{"GL-Backend", "platform/backend"},
+		MaintainerOf: []string{"platform/legacy"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
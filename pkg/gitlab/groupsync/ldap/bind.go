@@ -0,0 +1,145 @@
+package ldap
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/go-ldap/ldap/v3/gssapi"
+)
+
+// Podporovane metody autentizace vuci LDAPu
+const (
+	BindSimple   = "simple"
+	BindExternal = "external"
+	BindNTLM     = "ntlm"
+	BindGSSAPI   = "gssapi"
+)
+
+const defaultKrb5Conf = "/etc/krb5.conf"
+
+// validate checks that the configuration contains everything the selected bind method needs
+func (c LDAPConfig) validate() error {
+	switch c.bindMethod() {
+	case BindSimple:
+		if c.BindDN == "" || c.Password == "" {
+			return fmt.Errorf("simple bind requires bind DN and password")
+		}
+	case BindExternal:
+		if c.ClientCert == "" || c.ClientKey == "" {
+			return fmt.Errorf("SASL EXTERNAL bind requires client certificate and key")
+		}
+		if !c.StartTLS && !strings.HasPrefix(strings.ToLower(c.Host), "ldaps://") {
+			return fmt.Errorf("SASL EXTERNAL bind requires ldaps:// or StartTLS")
+		}
+	case BindNTLM:
+		if c.Username == "" || c.Password == "" {
+			return fmt.Errorf("NTLM bind requires username and password")
+		}
+	case BindGSSAPI:
+		if c.Username == "" {
+			return fmt.Errorf("GSSAPI bind requires username (Kerberos principal)")
+		}
+		if c.Keytab == "" && c.Password == "" {
+			return fmt.Errorf("GSSAPI bind requires keytab or password")
+		}
+	default:
+		return fmt.Errorf("unsupported bind method %q (supported: %s, %s, %s, %s)",
+			c.BindMethod, BindSimple, BindExternal, BindNTLM, BindGSSAPI)
+	}
+	return nil
+}
+
+func (c LDAPConfig) bindMethod() string {
+	if c.BindMethod == "" {
+		return BindSimple
+	}
+	return strings.ToLower(c.BindMethod)
+}
+
+// tlsConfig builds TLS settings from CA bundle and client certificate
+func (c LDAPConfig) tlsConfig() (*tls.Config, error) {
+	u, err := url.Parse(c.Host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LDAP host %q: %w", c.Host, err)
+	}
+
+	config := &tls.Config{
+		ServerName:         u.Hostname(),
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	if c.CACert != "" {
+		pem, err := os.ReadFile(c.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("error reading CA certificate: %w", err)
+		}
+		// Vlastni CA pridame k systemovym, aby fungovaly i verejne certifikaty
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", c.CACert)
+		}
+		config.RootCAs = pool
+	}
+
+	if c.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(c.ClientCert, c.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// bind authenticates the connection using the configured method
+func (c LDAPConfig) bind(conn *ldap.Conn) error {
+	switch c.bindMethod() {
+	case BindExternal:
+		return conn.ExternalBind()
+	case BindNTLM:
+		return conn.NTLMBind(c.Domain, c.Username, c.Password)
+	case BindGSSAPI:
+		return c.gssapiBind(conn)
+	default:
+		return conn.Bind(c.BindDN, c.Password)
+	}
+}
+
+func (c LDAPConfig) gssapiBind(conn *ldap.Conn) error {
+	krb5Conf := c.Krb5Conf
+	if krb5Conf == "" {
+		krb5Conf = defaultKrb5Conf
+	}
+
+	var client *gssapi.Client
+	var err error
+	if c.Keytab != "" {
+		client, err = gssapi.NewClientWithKeytab(c.Username, c.Realm, c.Keytab, krb5Conf)
+	} else {
+		client, err = gssapi.NewClientWithPassword(c.Username, c.Realm, c.Password, krb5Conf)
+	}
+	if err != nil {
+		return fmt.Errorf("error creating Kerberos client: %w", err)
+	}
+	defer client.Close()
+
+	spn := c.ServicePrincipal
+	if spn == "" {
+		u, err := url.Parse(c.Host)
+		if err != nil {
+			return fmt.Errorf("invalid LDAP host %q: %w", c.Host, err)
+		}
+		spn = "ldap/" + u.Hostname()
+	}
+
+	return conn.GSSAPIBind(client, spn, "")
+}
@@ -0,0 +1,276 @@
+package ldap
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/Cloud-for-You/devops-cli/pkg/gitlab/groupsync"
+	"github.com/Cloud-for-You/devops-cli/pkg/metrics"
+)
+
+// Rezimy inkrementalni synchronizace
+const (
+	// IncrementalTimestamp finds changed groups by whenChanged/modifyTimestamp.
+	// whenChanged is not replicated in Active Directory, always connect to the same DC.
+	IncrementalTimestamp = "timestamp"
+	// IncrementalDirSync uses the Active Directory DirSync control and its cookie
+	IncrementalDirSync = "dirsync"
+)
+
+const generalizedTimeLayout = "20060102150405Z"
+
+// SyncState is the progress of incremental synchronization persisted between runs
+type SyncState struct {
+	Mode string `json:"mode"`
+	// Selection is a fingerprint of the group selection, a change forces a full sync
+	Selection    string    `json:"selection"`
+	Watermark    time.Time `json:"watermark,omitempty"`
+	Cookie       []byte    `json:"cookie,omitempty"`
+	LastFullSync time.Time `json:"lastFullSync,omitempty"`
+	LastSync     time.Time `json:"lastSync,omitempty"`
+}
+
+// IncrementalConfig controls ReadChangedGroups
+type IncrementalConfig struct {
+	Mode string
+	// FullSyncInterval forces a full sync when the last one is older, 0 disables it
+	FullSyncInterval time.Duration
+	// ForceFull ignores the state and reads all groups
+	ForceFull bool
+}
+
+// LoadSyncState reads the state file, a missing file yields an empty state
+func LoadSyncState(path string) (*SyncState, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &SyncState{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading sync state: %w", err)
+	}
+
+	state := &SyncState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("invalid sync state %s: %w", path, err)
+	}
+	return state, nil
+}
+
+// Save writes the state atomically, a crash never leaves a truncated file
+func (s *SyncState) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".groupsync-state-*")
+	if err != nil {
+		return fmt.Errorf("error writing sync state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing sync state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing sync state: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// fingerprint identifies the directory and the group selection the state belongs to
+func fingerprint(config LDAPConfig, selection GroupSelection) string {
+	data, _ := json.Marshal([]interface{}{config.Host, selection})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ReadChangedGroups returns groups changed since the last run recorded in state
+// together with their members. A full read is done on the first run, after the
+// selection changed, when the last full sync is older than FullSyncInterval or
+// when ForceFull is set; full reports which one happened. The state is updated
+// in place, the caller saves it only after the changes were applied.
+func ReadChangedGroups(config LDAPConfig, selection GroupSelection, state *SyncState, inc IncrementalConfig) (groups []groupsync.Group, full bool, err error) {
+	mode := inc.Mode
+	if mode == "" {
+		mode = IncrementalTimestamp
+	}
+	if mode != IncrementalTimestamp && mode != IncrementalDirSync {
+		return nil, false, fmt.Errorf("unsupported incremental mode %q (supported: %s, %s)", mode, IncrementalTimestamp, IncrementalDirSync)
+	}
+
+	selectionID := fingerprint(config, selection)
+	full = inc.ForceFull ||
+		state.Mode != mode ||
+		state.Selection != selectionID ||
+		state.LastFullSync.IsZero() ||
+		(inc.FullSyncInterval > 0 && time.Since(state.LastFullSync) >= inc.FullSyncInterval)
+
+	connector, err := NewLDAPConnector(config)
+	if err != nil {
+		return nil, false, err
+	}
+	defer connector.Close()
+
+	groupSyncer, err := NewLDAPGroupSyncer(connector, selection)
+	if err != nil {
+		return nil, false, err
+	}
+
+	now := time.Now()
+	var entries *ldap.SearchResult
+
+	switch {
+	case mode == IncrementalDirSync:
+		// Cookie ziskame i pri plne synchronizaci, dalsi beh pak vidi jen zmeny
+		cookie := state.Cookie
+		if full {
+			cookie = nil
+		}
+		changedDNs, newCookie, err := groupSyncer.dirSyncChangedGroups(cookie)
+		if err != nil {
+			return nil, false, err
+		}
+		if full {
+			entries, err = groupSyncer.GetLdapGroups()
+		} else {
+			entries, err = groupSyncer.GetLdapGroupsByDN(changedDNs)
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		state.Cookie = newCookie
+
+	case full:
+		entries, err = groupSyncer.GetLdapGroups()
+		if err != nil {
+			return nil, false, err
+		}
+		state.Watermark = latestChange(entries.Entries, time.Time{})
+
+	default:
+		entries, err = groupSyncer.GetLdapGroupsChangedSince(state.Watermark)
+		if err != nil {
+			return nil, false, err
+		}
+		state.Watermark = latestChange(entries.Entries, state.Watermark)
+	}
+
+	groups, err = readMembers(connector, groupSyncer, entries.Entries)
+	if err != nil {
+		return nil, false, err
+	}
+
+	state.Mode = mode
+	state.Selection = selectionID
+	state.LastSync = now
+	if full {
+		state.LastFullSync = now
+	}
+
+	return groups, full, nil
+}
+
+// latestChange returns the newest change time of the entries, at least since.
+// Server time is used on purpose, local clock may be skewed.
+func latestChange(entries []*ldap.Entry, since time.Time) time.Time {
+	latest := since
+	for _, entry := range entries {
+		for _, attribute := range []string{"whenChanged", "modifyTimestamp"} {
+			value := entry.GetAttributeValue(attribute)
+			if value == "" {
+				continue
+			}
+			changed, err := ber.ParseGeneralizedTime([]byte(value))
+			if err == nil && changed.After(latest) {
+				latest = changed
+			}
+		}
+	}
+	return latest
+}
+
+// dirSyncChangedGroups returns DNs of groups changed since the cookie was issued,
+// including changes of their members, and the new cookie. DirSync must be run
+// against the root of the naming context.
+func (s *LDAPGroupSyncer) dirSyncChangedGroups(cookie []byte) ([]string, []byte, error) {
+	roots := make(map[string]struct{})
+	for _, dn := range append(append([]string{}, s.baseDNs...), s.groupDNs...) {
+		roots[namingContext(dn)] = struct{}{}
+	}
+	if len(roots) != 1 {
+		return nil, nil, fmt.Errorf("DirSync requires all groups to be in one naming context")
+	}
+
+	var root string
+	for r := range roots {
+		root = r
+	}
+
+	var changed []string
+	seen := make(map[string]struct{})
+	for {
+		// DirSync vraci jen objekty, u kterych se zmenil nektery z pozadovanych atributu,
+		// bez "member" by zmeny clenstvi nevidel. Hodnoty nepotrebujeme, skupinu
+		// nacteme znovu cela, proto staci jen zmenene hodnoty (IncrementalValues).
+		searchRequest := ldap.NewSearchRequest(
+			root,
+			ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+			s.groupFilter,
+			[]string{"cn", "member"},
+			nil,
+		)
+
+		start := time.Now()
+		result, err := s.connector.conn.DirSync(searchRequest, ldap.DirSyncObjectSecurity|ldap.DirSyncIncrementalValues, 0, cookie)
+		metrics.ObserveLDAP("dirsync", start, err)
+		if err != nil {
+			return nil, nil, fmt.Errorf("DirSync search failed: %w", err)
+		}
+
+		// Skupina se muze opakovat v dalsich strankach s dalsimi hodnotami member
+		for _, entry := range result.Entries {
+			if _, ok := seen[strings.ToLower(entry.DN)]; ok {
+				continue
+			}
+			seen[strings.ToLower(entry.DN)] = struct{}{}
+			changed = append(changed, entry.DN)
+		}
+
+		control := ldap.FindControl(result.Controls, ldap.ControlTypeDirSync)
+		if control == nil {
+			return nil, nil, fmt.Errorf("server did not return DirSync control, is it Active Directory?")
+		}
+		dirSync := control.(*ldap.ControlDirSync)
+		cookie = dirSync.Cookie
+
+		// Nenulove flags znamenaji, ze server ma dalsi data
+		if dirSync.Flags == 0 {
+			break
+		}
+	}
+
+	return changed, cookie, nil
+}
+
+// namingContext returns the DC= suffix of the DN, e.g. DC=example,DC=com
+func namingContext(dn string) string {
+	parts := strings.Split(dn, ",")
+	for i, part := range parts {
+		if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(part)), "DC=") {
+			return strings.Join(parts[i:], ",")
+		}
+	}
+	return dn
+}
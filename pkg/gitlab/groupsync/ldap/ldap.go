@@ -2,8 +2,14 @@ package ldap
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-ldap/ldap/v3"
+
+	"github.com/Cloud-for-You/devops-cli/pkg/metrics"
 )
 
 type LDAPConfig struct {
@@ -11,6 +17,26 @@ type LDAPConfig struct {
 	BindDN   string
 	Password string
 	BaseDN   string
+
+	// BindMethod is one of simple (default), external, ntlm or gssapi
+	BindMethod string
+
+	// TLS nastaveni (ldaps:// nebo StartTLS)
+	StartTLS           bool
+	CACert             string
+	ClientCert         string
+	ClientKey          string
+	InsecureSkipVerify bool
+
+	// NTLM a GSSAPI
+	Username string
+	Domain   string
+
+	// GSSAPI (Kerberos)
+	Realm            string
+	Keytab           string
+	Krb5Conf         string
+	ServicePrincipal string
 }
 
 type LDAPConnector struct {
@@ -18,22 +44,57 @@ type LDAPConnector struct {
 	baseDN string
 }
 
+// GroupSelection describes which LDAP groups are synchronized.
+// Explicit DNs are always read (they still have to pass Include/Exclude),
+// every BaseDN is searched with Filter. Include and Exclude are regular
+// expressions matched against the group cn, Exclude wins over Include.
+type GroupSelection struct {
+	DNs     []string
+	BaseDNs []string
+	Filter  string
+	Include []string
+	Exclude []string
+}
+
 type LDAPGroupSyncer struct {
 	connector   *LDAPConnector
 	groupFilter string
+	groupDNs    []string
+	baseDNs     []string
+	include     []*regexp.Regexp
+	exclude     []*regexp.Regexp
 }
 
 func NewLDAPConnector(config LDAPConfig) (*LDAPConnector, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := config.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
 	// Pripojeni k LDAPu
-	conn, err := ldap.DialURL(config.Host)
+	conn, err := ldap.DialURL(config.Host, ldap.DialWithTLSConfig(tlsConfig))
 	if err != nil {
 		return nil, err
 	}
 
+	if config.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("error starting TLS: %w", err)
+		}
+	}
+
 	// Autentizace uzivatele
-	err = conn.Bind(config.BindDN, config.Password)
+	start := time.Now()
+	err = config.bind(conn)
+	metrics.ObserveLDAP("bind", start, err)
 	if err != nil {
-		return nil, err
+		conn.Close()
+		return nil, fmt.Errorf("%s bind failed: %w", config.bindMethod(), err)
 	}
 
 	// Vytvoreni instance
@@ -43,42 +104,140 @@ func NewLDAPConnector(config LDAPConfig) (*LDAPConnector, error) {
 	}, nil
 }
 
+// search executes the request and records its count and latency under operation
+func (c *LDAPConnector) search(operation string, searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	start := time.Now()
+	result, err := c.conn.Search(searchRequest)
+	metrics.ObserveLDAP(operation, start, err)
+	return result, err
+}
+
 func (l *LDAPConnector) Close() {
 	if l.conn != nil {
 		l.conn.Close()
 	}
 }
 
-func NewLDAPGroupSyncer(connector *LDAPConnector, groupFilter string) *LDAPGroupSyncer {
+func NewLDAPGroupSyncer(connector *LDAPConnector, selection GroupSelection) (*LDAPGroupSyncer, error) {
+	include, err := compilePatterns(selection.Include)
+	if err != nil {
+		return nil, fmt.Errorf("invalid include pattern: %w", err)
+	}
+	exclude, err := compilePatterns(selection.Exclude)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exclude pattern: %w", err)
+	}
+
+	filter := selection.Filter
+	if filter == "" {
+		filter = "(objectClass=group)"
+	}
+
+	// Bez explicitnich DN a bez search base pouzijeme base DN konektoru
+	baseDNs := selection.BaseDNs
+	if len(baseDNs) == 0 && len(selection.DNs) == 0 {
+		if connector.baseDN == "" {
+			return nil, fmt.Errorf("at least one group DN or search base must be specified")
+		}
+		baseDNs = []string{connector.baseDN}
+	}
+
 	return &LDAPGroupSyncer{
 		connector:   connector,
-		groupFilter: groupFilter,
+		groupFilter: filter,
+		groupDNs:    selection.DNs,
+		baseDNs:     baseDNs,
+		include:     include,
+		exclude:     exclude,
+	}, nil
+}
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", p, err)
+		}
+		compiled = append(compiled, re)
 	}
+	return compiled, nil
 }
 
+// selected reports whether a group with the given cn passes include/exclude patterns
+func (s *LDAPGroupSyncer) selected(cn string) bool {
+	for _, re := range s.exclude {
+		if re.MatchString(cn) {
+			return false
+		}
+	}
+	if len(s.include) == 0 {
+		return true
+	}
+	for _, re := range s.include {
+		if re.MatchString(cn) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListLdapGroupMemberDNs returns DNs of all members of the group, large groups
+// are read in ranges
 func (s *LDAPGroupSyncer) ListLdapGroupMemberDNs(groupDN string) ([]string, error) {
-	// Definice vyhledavaciho pozadavku
-	searchRequest := ldap.NewSearchRequest(
-		groupDN, // Zakladni DN
-		ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false,
-		s.groupFilter, // Filtr pro vyhledani
-		[]string{"member"},
-		nil,
-	)
+	var members []string
+	attribute := "member"
+	for {
+		// Definice vyhledavaciho pozadavku
+		searchRequest := ldap.NewSearchRequest(
+			groupDN, // Zakladni DN
+			ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false,
+			"(objectClass=*)", // Skupina je dana primo DN, filtr nezuzujeme
+			[]string{attribute},
+			nil,
+		)
 
-	// Provedeme vyhledani v LDAPu
-	result, err := s.connector.conn.Search(searchRequest)
-	if err != nil {
-		return nil, err
-	}
+		// Provedeme vyhledani v LDAPu
+		result, err := s.connector.search("group_members", searchRequest)
+		if err != nil {
+			return nil, err
+		}
 
-	if len(result.Entries) == 0 {
-		return nil, fmt.Errorf("not found members for group %s", groupDN)
-	}
+		if len(result.Entries) == 0 {
+			return nil, fmt.Errorf("not found members for group %s", groupDN)
+		}
 
-	members := result.Entries[0].GetAttributeValues("member")
+		values, next, done := memberRange(result.Entries[0])
+		members = append(members, values...)
+		if done {
+			return members, nil
+		}
+		attribute = fmt.Sprintf("member;range=%d-*", next)
+	}
+}
 
-	return members, nil
+// memberRange returns values of the "member" attribute of the entry. Active Directory
+// returns at most MaxValRange (1500) values at once as "member;range=0-1499", then
+// next is the start of the following range and done is false. done is set for the
+// last range ("member;range=1500-*"), a plain "member" attribute or a group without members.
+func memberRange(entry *ldap.Entry) (values []string, next int, done bool) {
+	for _, attribute := range entry.Attributes {
+		name := strings.ToLower(attribute.Name)
+		if name == "member" {
+			return attribute.Values, 0, true
+		}
+		spec, ok := strings.CutPrefix(name, "member;range=")
+		if !ok {
+			continue
+		}
+		_, end, _ := strings.Cut(spec, "-")
+		last, err := strconv.Atoi(end)
+		if end == "*" || err != nil {
+			return attribute.Values, 0, true
+		}
+		return attribute.Values, last + 1, false
+	}
+	return nil, 0, true
 }
 
 func (c *LDAPConnector) GetLdapUserAttributes(userDN string, attributes []string) (*ldap.SearchResult, error) {
@@ -92,7 +251,7 @@ func (c *LDAPConnector) GetLdapUserAttributes(userDN string, attributes []string
 	)
 
 	// Provedeme vyhledani v LDAPu
-	result, err := c.conn.Search(searchRequest)
+	result, err := c.search("user", searchRequest)
 	if err != nil {
 		return nil, err
 	}
@@ -104,21 +263,145 @@ func (c *LDAPConnector) GetLdapUserAttributes(userDN string, attributes []string
 	return result, nil
 }
 
+// Atributy skupin, casy zmen potrebujeme pro inkrementalni synchronizaci
+var groupAttributes = []string{"*", "whenChanged", "modifyTimestamp"}
+
 func (s *LDAPGroupSyncer) GetLdapGroups() (*ldap.SearchResult, error) {
-	// Definice vyhledavaciho pozadavku
-	searchRequest := ldap.NewSearchRequest(
-		s.connector.baseDN, // Zakladni DN
-		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
-		s.groupFilter, // Filtr pro vyhledani
-		nil,
-		nil,
-	)
+	return s.getGroups("")
+}
 
-	// Provedeme vyhledani v LDAPu
-	result, err := s.connector.conn.Search(searchRequest)
-	if err != nil {
-		return nil, err
+// GetLdapGroupsChangedSince returns selected groups modified at or after since.
+// Active Directory reports changes in whenChanged, other servers in modifyTimestamp.
+func (s *LDAPGroupSyncer) GetLdapGroupsChangedSince(since time.Time) (*ldap.SearchResult, error) {
+	stamp := since.UTC().Format(generalizedTimeLayout)
+	return s.getGroups(fmt.Sprintf("(|(whenChanged>=%s)(modifyTimestamp>=%s))", stamp, stamp))
+}
+
+// getGroups reads explicit groups and searches all bases, extraFilter narrows both
+func (s *LDAPGroupSyncer) getGroups(extraFilter string) (*ldap.SearchResult, error) {
+	result := &ldap.SearchResult{}
+	seen := make(map[string]struct{})
+
+	collect := func(entries []*ldap.Entry) {
+		for _, entry := range entries {
+			if _, ok := seen[entry.DN]; ok {
+				continue
+			}
+			seen[entry.DN] = struct{}{}
+			if s.selected(entry.GetAttributeValue("cn")) {
+				result.Entries = append(result.Entries, entry)
+			}
+		}
+	}
+
+	// Explicitne zadane skupiny nacteme primo podle DN
+	for _, dn := range s.groupDNs {
+		searchRequest := ldap.NewSearchRequest(
+			dn,
+			ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false,
+			andFilter("(objectClass=*)", extraFilter),
+			groupAttributes,
+			nil,
+		)
+
+		found, err := s.connector.search("group", searchRequest)
+		if err != nil {
+			return nil, fmt.Errorf("error reading group %s: %w", dn, err)
+		}
+		collect(found.Entries)
+	}
+
+	// Prohledame vsechny search base
+	for _, baseDN := range s.baseDNs {
+		searchRequest := ldap.NewSearchRequest(
+			baseDN, // Zakladni DN
+			ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+			andFilter(s.groupFilter, extraFilter), // Filtr pro vyhledani
+			groupAttributes,
+			nil,
+		)
+
+		found, err := s.connector.search("groups", searchRequest)
+		if err != nil {
+			return nil, fmt.Errorf("error searching groups in %s: %w", baseDN, err)
+		}
+		collect(found.Entries)
 	}
 
 	return result, nil
 }
+
+// GetLdapGroupsByDN reads the given groups and returns those that belong to the
+// selection (explicit DN or matching the filter under one of the search bases)
+func (s *LDAPGroupSyncer) GetLdapGroupsByDN(dns []string) (*ldap.SearchResult, error) {
+	result := &ldap.SearchResult{}
+
+	for _, dn := range dns {
+		filter := ""
+		if containsDN(s.groupDNs, dn) {
+			filter = "(objectClass=*)"
+		} else {
+			for _, baseDN := range s.baseDNs {
+				if isUnderDN(dn, baseDN) {
+					filter = s.groupFilter
+					break
+				}
+			}
+		}
+		if filter == "" {
+			continue
+		}
+
+		searchRequest := ldap.NewSearchRequest(
+			dn,
+			ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false,
+			filter,
+			groupAttributes,
+			nil,
+		)
+
+		found, err := s.connector.search("group", searchRequest)
+		if err != nil {
+			if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+				continue
+			}
+			return nil, fmt.Errorf("error reading group %s: %w", dn, err)
+		}
+		for _, entry := range found.Entries {
+			if s.selected(entry.GetAttributeValue("cn")) {
+				result.Entries = append(result.Entries, entry)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func andFilter(filter, extra string) string {
+	if extra == "" {
+		return filter
+	}
+	return "(&" + filter + extra + ")"
+}
+
+func containsDN(dns []string, dn string) bool {
+	for _, d := range dns {
+		if strings.EqualFold(d, dn) {
+			return true
+		}
+	}
+	return false
+}
+
+// isUnderDN reports whether dn equals base or lies in its subtree
+func isUnderDN(dn, base string) bool {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil {
+		return false
+	}
+	parsedBase, err := ldap.ParseDN(base)
+	if err != nil {
+		return false
+	}
+	return parsed.EqualFold(parsedBase) || parsedBase.AncestorOfFold(parsed)
+}
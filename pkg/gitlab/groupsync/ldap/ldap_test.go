@@ -0,0 +1,71 @@
+package ldap
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+func TestMemberRange(t *testing.T) {
+	tests := []struct {
+		name       string
+		attributes map[string][]string
+		want       []string
+		next       int
+		done       bool
+	}{
+		{
+			name:       "all members at once",
+			attributes: map[string][]string{"member": {"CN=a", "CN=b"}},
+			want:       []string{"CN=a", "CN=b"},
+			done:       true,
+		},
+		{
+			name:       "first range",
+			attributes: map[string][]string{"member;range=0-1499": {"CN=a"}},
+			want:       []string{"CN=a"},
+			next:       1500,
+		},
+		{
+			name:       "last range",
+			attributes: map[string][]string{"member;range=1500-*": {"CN=b"}},
+			want:       []string{"CN=b"},
+			done:       true,
+		},
+		{
+			name:       "attribute name case",
+			attributes: map[string][]string{"Member;Range=0-999": {"CN=a"}},
+			want:       []string{"CN=a"},
+			next:       1000,
+		},
+		{
+			name:       "group without members",
+			attributes: map[string][]string{"cn": {"GL-Backend"}},
+			done:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := ldap.NewEntry("CN=GL-Backend,OU=Groups,DC=example,DC=com", tt.attributes)
+			values, next, done := memberRange(entry)
+			if !reflect.DeepEqual(values, tt.want) || next != tt.next || done != tt.done {
+				t.Errorf("got %v, %d, %v, want %v, %d, %v", values, next, done, tt.want, tt.next, tt.done)
+			}
+		})
+	}
+}
+
+func TestNamingContext(t *testing.T) {
+	tests := map[string]string{
+		"OU=Groups,DC=example,DC=com":                       "DC=example,DC=com",
+		"CN=GL-Backend,OU=Groups,dc=corp,dc=example,dc=com": "dc=corp,dc=example,dc=com",
+		"O=example": "O=example",
+	}
+	for dn, want := range tests {
+		if got := namingContext(dn); got != want {
+			t.Errorf("namingContext(%q) = %q, want %q", dn, got, want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package ldap
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/go-ldap/ldap/v3"
+
+	common "github.com/Cloud-for-You/devops-cli/pkg"
+	"github.com/Cloud-for-You/devops-cli/pkg/gitlab/groupsync"
+)
+
+// ReadGroups connects to the directory server and returns the selected groups
+// with their members. GitLab username is taken from sAMAccountName.
+func ReadGroups(config LDAPConfig, selection GroupSelection) ([]groupsync.Group, error) {
+	connector, err := NewLDAPConnector(config)
+	if err != nil {
+		return nil, err
+	}
+	defer connector.Close()
+
+	groupSyncer, err := NewLDAPGroupSyncer(connector, selection)
+	if err != nil {
+		return nil, err
+	}
+
+	// Nacteni skupin z LDAPu
+	ldapGroups, err := groupSyncer.GetLdapGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	return readMembers(connector, groupSyncer, ldapGroups.Entries)
+}
+
+// readMembers converts LDAP group entries to sync groups with their members
+func readMembers(connector *LDAPConnector, groupSyncer *LDAPGroupSyncer, entries []*ldap.Entry) ([]groupsync.Group, error) {
+	var groups []groupsync.Group
+	for _, entry := range entries {
+		group := groupsync.Group{Name: entry.GetAttributeValue("cn")}
+
+		// Ziskani seznamu clenu skupiny z LDAPu
+		memberDNs, err := groupSyncer.ListLdapGroupMemberDNs(entry.DN)
+		if err != nil {
+			return nil, fmt.Errorf("error listing LDAP group members: %w", err)
+		}
+
+		for _, dn := range memberDNs {
+			userAttributes, err := connector.GetLdapUserAttributes(dn, nil)
+			if err != nil {
+				return nil, fmt.Errorf("error getting attributes for user %s: %w", dn, err)
+			}
+			user := userAttributes.Entries[0]
+			username := user.GetAttributeValue("sAMAccountName")
+			if username == "" {
+				// Napr. vnorene skupiny nebo kontakty nemaji GitLab uzivatele
+				slog.Warn("LDAP member without sAMAccountName skipped", "group", group.Name, "dn", user.DN)
+				continue
+			}
+			group.Members = append(group.Members, common.Member{
+				Name:       username,
+				Email:      user.GetAttributeValue("mail"),
+				ExternalID: user.DN,
+				Source:     "ldap",
+			})
+		}
+
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
@@ -0,0 +1,173 @@
+package groupsync
+
+import (
+	"fmt"
+	"strings"
+
+	gitlabapi "github.com/Cloud-for-You/devops-cli/pkg/gitlab"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// Kinds of objects a team group is shared into
+const (
+	ShareKindGroup   = "group"
+	ShareKindProject = "project"
+)
+
+// ShareChangeType is the kind of change of a share
+type ShareChangeType string
+
+const (
+	ShareAdd    ShareChangeType = "share"
+	ShareUpdate ShareChangeType = "update-share"
+)
+
+// ShareTarget is a group or project in the "shareInto" list of a team
+type ShareTarget struct {
+	Group       string `mapstructure:"group"`
+	Project     string `mapstructure:"project"`
+	AccessLevel string `mapstructure:"accessLevel"`
+}
+
+// Team maps a source group to groups and projects. The source group is synchronized
+// once into its GitLab "team" group, which is then shared into all targets instead
+// of copying the members into each of them.
+type Team struct {
+	Group     string        `mapstructure:"group"`
+	ShareInto []ShareTarget `mapstructure:"shareInto"`
+}
+
+// Share is a group or project the synchronized group should be shared into
+type Share struct {
+	Kind        string
+	Path        string
+	AccessLevel gitlab.AccessLevelValue
+}
+
+// ShareChange is a share of the synchronized group to create or change
+type ShareChange struct {
+	Type        ShareChangeType         `json:"type"`
+	Kind        string                  `json:"kind"`
+	Target      string                  `json:"target"`
+	AccessLevel gitlab.AccessLevelValue `json:"accessLevel"`
+	// Current is the access level of an existing share which is updated
+	Current gitlab.AccessLevelValue `json:"current,omitempty"`
+}
+
+// FailedShare is a share that could not be applied
+type FailedShare struct {
+	Change ShareChange `json:"change"`
+	Error  string      `json:"error"`
+}
+
+// MapTeams sets shares of source groups according to teams. Teams whose group was
+// not read from the source (e.g. unchanged in an incremental run) are ignored.
+func MapTeams(groups []Group, teams []Team) ([]Group, error) {
+	shares := map[string][]Share{}
+	for _, team := range teams {
+		if team.Group == "" {
+			return nil, fmt.Errorf("team without group")
+		}
+		for _, target := range team.ShareInto {
+			share := Share{Kind: ShareKindGroup, Path: target.Group}
+			switch {
+			case target.Group != "" && target.Project != "":
+				return nil, fmt.Errorf("team %s: target has both group %s and project %s", team.Group, target.Group, target.Project)
+			case target.Project != "":
+				share = Share{Kind: ShareKindProject, Path: target.Project}
+			case target.Group == "":
+				return nil, fmt.Errorf("team %s: target without group or project", team.Group)
+			}
+
+			level, err := gitlabapi.ParseAccessLevel(target.AccessLevel)
+			if err != nil {
+				return nil, fmt.Errorf("team %s, %s %s: %w", team.Group, share.Kind, share.Path, err)
+			}
+			share.AccessLevel = level
+
+			key := strings.ToLower(team.Group)
+			shares[key] = append(shares[key], share)
+		}
+	}
+
+	for i := range groups {
+		groups[i].Shares = shares[strings.ToLower(groups[i].Name)]
+	}
+	return groups, nil
+}
+
+// planShares compares desired shares of the group with its shares in GitLab,
+// a group which does not exist yet is shared into all targets. Shares which are
+// not in the mapping are never removed.
+func planShares(client *gitlab.Client, group Group, create bool) ([]ShareChange, error) {
+	var changes []ShareChange
+	for _, share := range group.Shares {
+		change := ShareChange{Type: ShareAdd, Kind: share.Kind, Target: share.Path, AccessLevel: share.AccessLevel}
+		if create {
+			changes = append(changes, change)
+			continue
+		}
+
+		current, err := currentShare(client, share, group.Name)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case current == 0:
+			changes = append(changes, change)
+		case current != share.AccessLevel:
+			change.Type, change.Current = ShareUpdate, current
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+// currentShare returns the access level of the group in the target, 0 when the
+// target is not shared with the group. A missing target is reported when applying.
+func currentShare(client *gitlab.Client, share Share, groupName string) (gitlab.AccessLevelValue, error) {
+	if share.Kind == ShareKindProject {
+		project, err := gitlabapi.GetProject(client, share.Path)
+		if err != nil {
+			if gitlabapi.IsNotFound(err) {
+				return 0, nil
+			}
+			return 0, fmt.Errorf("error retrieving GitLab project '%s': %w", share.Path, err)
+		}
+		for _, s := range project.SharedWithGroups {
+			if strings.EqualFold(s.GroupFullPath, groupName) {
+				return gitlab.AccessLevelValue(s.GroupAccessLevel), nil
+			}
+		}
+		return 0, nil
+	}
+
+	target, err := gitlabapi.GetGroup(client, share.Path)
+	if err != nil {
+		if gitlabapi.IsNotFound(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("error retrieving GitLab group '%s': %w", share.Path, err)
+	}
+	for _, s := range target.SharedWithGroups {
+		if strings.EqualFold(s.GroupFullPath, groupName) {
+			return gitlab.AccessLevelValue(s.GroupAccessLevel), nil
+		}
+	}
+	return 0, nil
+}
+
+func applyShare(client *gitlab.Client, groupName string, change ShareChange) error {
+	share, unshare := gitlabapi.ShareGroup, gitlabapi.UnshareGroup
+	if change.Kind == ShareKindProject {
+		share, unshare = gitlabapi.ShareProject, gitlabapi.UnshareProject
+	}
+
+	// Sdileni nelze upravit, odebereme ho a vytvorime znovu
+	if change.Type == ShareUpdate {
+		if err := unshare(client, change.Target, groupName); err != nil {
+			return err
+		}
+	}
+	return share(client, change.Target, groupName, change.AccessLevel, nil)
+}
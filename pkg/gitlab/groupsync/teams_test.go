@@ -0,0 +1,115 @@
+package groupsync
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+func TestMapTeams(t *testing.T) {
+	groups := []Group{{Name: "GL-Backend"}, {Name: "GL-Frontend"}, {Name: "GL-Ops"}}
+	teams := []Team{
+		{Group: "gl-backend", ShareInto: []ShareTarget{
+			{Group: "platform/backend", AccessLevel: "developer"},
+			{Project: "platform/legacy", AccessLevel: "40"},
+		}},
+		{Group: "GL-Ops", ShareInto: []ShareTarget{{Group: "platform", AccessLevel: "reporter"}}},
+		// Skupina neni ve zdroji, tym se ignoruje
+		{Group: "GL-Unknown", ShareInto: []ShareTarget{{Group: "platform", AccessLevel: "guest"}}},
+	}
+
+	mapped, err := MapTeams(groups, teams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]Share{
+		{
+			{Kind: ShareKindGroup, Path: "platform/backend", AccessLevel: gitlab.DeveloperPermissions},
+			{Kind: ShareKindProject, Path: "platform/legacy", AccessLevel: gitlab.MaintainerPermissions},
+		},
+		nil,
+		{{Kind: ShareKindGroup, Path: "platform", AccessLevel: gitlab.ReporterPermissions}},
+	}
+	for i, group := range mapped {
+		if !reflect.DeepEqual(group.Shares, want[i]) {
+			t.Errorf("%s: got %v, want %v", group.Name, group.Shares, want[i])
+		}
+	}
+}
+
+func TestMapTeamsErrors(t *testing.T) {
+	tests := map[string]Team{
+		"team without group":                 {ShareInto: []ShareTarget{{Group: "platform", AccessLevel: "developer"}}},
+		"target has both group":              {Group: "GL-Backend", ShareInto: []ShareTarget{{Group: "platform", Project: "platform/legacy", AccessLevel: "developer"}}},
+		"target without group or project":    {Group: "GL-Backend", ShareInto: []ShareTarget{{AccessLevel: "developer"}}},
+		`invalid access level "contributor"`: {Group: "GL-Backend", ShareInto: []ShareTarget{{Group: "platform", AccessLevel: "contributor"}}},
+	}
+	for wantErr, team := range tests {
+		_, err := MapTeams([]Group{{Name: "GL-Backend"}}, []Team{team})
+		if err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("error %v, want %q", err, wantErr)
+		}
+	}
+}
+
+func TestPlanShares(t *testing.T) {
+	responses := map[string]string{
+		"/api/v4/groups/platform%2Fbackend":  `{"id": 2, "full_path": "platform/backend", "shared_with_groups": [{"group_id": 9, "group_full_path": "gl-backend", "group_access_level": 30}]}`,
+		"/api/v4/groups/platform":            `{"id": 1, "full_path": "platform", "shared_with_groups": [{"group_id": 9, "group_full_path": "GL-Backend", "group_access_level": 20}]}`,
+		"/api/v4/projects/platform%2Flegacy": `{"id": 5, "path_with_namespace": "platform/legacy", "shared_with_groups": []}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		body, ok := responses[r.URL.EscapedPath()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			body = `{"message": "404 Not found"}`
+		}
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client, err := gitlab.NewClient("token", gitlab.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	group := Group{Name: "GL-Backend", Shares: []Share{
+		{Kind: ShareKindGroup, Path: "platform/backend", AccessLevel: gitlab.DeveloperPermissions},
+		{Kind: ShareKindGroup, Path: "platform", AccessLevel: gitlab.DeveloperPermissions},
+		{Kind: ShareKindProject, Path: "platform/legacy", AccessLevel: gitlab.MaintainerPermissions},
+		{Kind: ShareKindGroup, Path: "platform/missing", AccessLevel: gitlab.DeveloperPermissions},
+	}}
+
+	changes, err := planShares(client, group, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ShareChange{
+		{Type: ShareUpdate, Kind: ShareKindGroup, Target: "platform", AccessLevel: gitlab.DeveloperPermissions, Current: gitlab.ReporterPermissions},
+		{Type: ShareAdd, Kind: ShareKindProject, Target: "platform/legacy", AccessLevel: gitlab.MaintainerPermissions},
+		{Type: ShareAdd, Kind: ShareKindGroup, Target: "platform/missing", AccessLevel: gitlab.DeveloperPermissions},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("got %+v, want %+v", changes, want)
+	}
+
+	// Nova skupina se sdili do vsech cilu bez dotazu na GitLab
+	server.Close()
+	changes, err = planShares(client, group, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != len(group.Shares) {
+		t.Errorf("got %d changes for a new group, want %d", len(changes), len(group.Shares))
+	}
+	for _, change := range changes {
+		if change.Type != ShareAdd {
+			t.Errorf("%s %s of a new group, want %s", change.Type, change.Target, ShareAdd)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package gitlab
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Cloud-for-You/devops-cli/pkg/audit"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// Funkce v tomto souboru adresuji skupiny a projekty ID nebo celou cestou
+
+// AddGroupMember adds a user to the group given by ID or full path,
+// a nil expiresAt adds the member without expiration
+func AddGroupMember(client *gitlab.Client, groupID string, username string, accessLevel gitlab.AccessLevelValue, expiresAt *time.Time) error {
+	userID, err := findUserID(client, username)
+	if err != nil {
+		return err
+	}
+
+	member, _, err := client.GroupMembers.AddGroupMember(groupID, &gitlab.AddGroupMemberOptions{
+		UserID:      &userID,
+		AccessLevel: &accessLevel,
+		ExpiresAt:   expiryDate(expiresAt),
+	})
+
+	event := audit.Event{
+		Action:  "group.member.add",
+		Target:  audit.Target{Type: "group", Name: groupID},
+		Subject: &audit.Subject{ID: userID, Username: username},
+	}
+	if member != nil {
+		event.After = memberState{Username: member.Username, AccessLevel: member.AccessLevel, ExpiresAt: member.ExpiresAt}
+	}
+	recordAudit(client, event, err)
+
+	if err != nil {
+		return fmt.Errorf("error adding user to group: %w", err)
+	}
+
+	slog.Debug("user added to group", "user", username, "group", groupID)
+	return nil
+}
+
+// EditGroupMember sets access level and expiration of a member of the group given by ID
+// or full path. The API requires the access level, callers changing only the expiration
+// pass the current level. A nil expiresAt removes the expiration.
+func EditGroupMember(client *gitlab.Client, groupID string, username string, accessLevel gitlab.AccessLevelValue, expiresAt *time.Time) error {
+	userID, err := findUserID(client, username)
+	if err != nil {
+		return err
+	}
+
+	// Prazdny retezec expiraci odstrani
+	expires := ""
+	if expiresAt != nil {
+		expires = expiresAt.Format(time.DateOnly)
+	}
+
+	before := groupMemberState(client, groupID, userID)
+	member, _, err := client.GroupMembers.EditGroupMember(groupID, userID, &gitlab.EditGroupMemberOptions{
+		AccessLevel: &accessLevel,
+		ExpiresAt:   &expires,
+	})
+
+	event := audit.Event{
+		Action:  "group.member.update",
+		Target:  audit.Target{Type: "group", Name: groupID},
+		Subject: &audit.Subject{ID: userID, Username: username},
+		Before:  before,
+	}
+	if member != nil {
+		event.After = memberState{Username: member.Username, AccessLevel: member.AccessLevel, ExpiresAt: member.ExpiresAt}
+	}
+	recordAudit(client, event, err)
+
+	if err != nil {
+		return fmt.Errorf("error updating group member: %w", err)
+	}
+
+	slog.Debug("group member updated", "user", username, "group", groupID)
+	return nil
+}
+
+// RemoveGroupMember removes a user from the group given by ID or full path
+func RemoveGroupMember(client *gitlab.Client, groupID string, username string) error {
+	userID, err := findUserID(client, username)
+	if err != nil {
+		return err
+	}
+
+	before := groupMemberState(client, groupID, userID)
+	_, err = client.GroupMembers.RemoveGroupMember(groupID, userID, nil)
+	recordAudit(client, audit.Event{
+		Action:  "group.member.remove",
+		Target:  audit.Target{Type: "group", Name: groupID},
+		Subject: &audit.Subject{ID: userID, Username: username},
+		Before:  before,
+	}, err)
+	if err != nil {
+		return fmt.Errorf("error removing user from group: %w", err)
+	}
+
+	slog.Debug("user removed from group", "user", username, "group", groupID)
+	return nil
+}
+
+// AddProjectMember adds a user to the project given by ID or full path,
+// a nil expiresAt adds the member without expiration
+func AddProjectMember(client *gitlab.Client, projectID string, username string, accessLevel gitlab.AccessLevelValue, expiresAt *time.Time) error {
+	userID, err := findUserID(client, username)
+	if err != nil {
+		return err
+	}
+
+	member, _, err := client.ProjectMembers.AddProjectMember(projectID, &gitlab.AddProjectMemberOptions{
+		UserID:      userID,
+		AccessLevel: &accessLevel,
+		ExpiresAt:   expiryDate(expiresAt),
+	})
+
+	event := audit.Event{
+		Action:  "project.member.add",
+		Target:  audit.Target{Type: "project", Name: projectID},
+		Subject: &audit.Subject{ID: userID, Username: username},
+	}
+	if member != nil {
+		event.After = memberState{Username: member.Username, AccessLevel: member.AccessLevel, ExpiresAt: member.ExpiresAt}
+	}
+	recordAudit(client, event, err)
+
+	if err != nil {
+		return fmt.Errorf("error adding user to project: %w", err)
+	}
+
+	slog.Debug("user added to project", "user", username, "project", projectID)
+	return nil
+}
+
+// EditProjectMember sets access level and expiration of a project member, see
+// EditGroupMember. A nil expiresAt removes the expiration.
+func EditProjectMember(client *gitlab.Client, projectID string, username string, accessLevel gitlab.AccessLevelValue, expiresAt *time.Time) error {
+	userID, err := findUserID(client, username)
+	if err != nil {
+		return err
+	}
+
+	expires := ""
+	if expiresAt != nil {
+		expires = expiresAt.Format(time.DateOnly)
+	}
+	before := projectMemberState(client, projectID, userID)
+	member, _, err := client.ProjectMembers.EditProjectMember(projectID, userID, &gitlab.EditProjectMemberOptions{
+		AccessLevel: &accessLevel,
+		ExpiresAt:   &expires,
+	})
+
+	event := audit.Event{
+		Action:  "project.member.update",
+		Target:  audit.Target{Type: "project", Name: projectID},
+		Subject: &audit.Subject{ID: userID, Username: username},
+		Before:  before,
+	}
+	if member != nil {
+		event.After = memberState{Username: member.Username, AccessLevel: member.AccessLevel, ExpiresAt: member.ExpiresAt}
+	}
+	recordAudit(client, event, err)
+
+	if err != nil {
+		return fmt.Errorf("error updating project member: %w", err)
+	}
+
+	slog.Debug("project member updated", "user", username, "project", projectID)
+	return nil
+}
+
+// RemoveProjectMember removes a user from the project given by ID or full path
+func RemoveProjectMember(client *gitlab.Client, projectID string, username string) error {
+	userID, err := findUserID(client, username)
+	if err != nil {
+		return err
+	}
+
+	before := projectMemberState(client, projectID, userID)
+	_, err = client.ProjectMembers.DeleteProjectMember(projectID, userID)
+	recordAudit(client, audit.Event{
+		Action:  "project.member.remove",
+		Target:  audit.Target{Type: "project", Name: projectID},
+		Subject: &audit.Subject{ID: userID, Username: username},
+		Before:  before,
+	}, err)
+	if err != nil {
+		return fmt.Errorf("error removing user from project: %w", err)
+	}
+
+	slog.Debug("user removed from project", "user", username, "project", projectID)
+	return nil
+}
+
+// expiryDate converts the expiration to the date format of the API, nil means no expiration
+func expiryDate(expiresAt *time.Time) *string {
+	if expiresAt == nil {
+		return nil
+	}
+	return gitlab.Ptr(expiresAt.Format(time.DateOnly))
+}
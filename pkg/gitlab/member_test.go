@@ -0,0 +1,117 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Cloud-for-You/devops-cli/pkg/audit"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+type memorySink struct {
+	events []audit.Event
+}
+
+func (s *memorySink) Write(event audit.Event) error {
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *memorySink) Close() error {
+	return nil
+}
+
+func TestMemberAuditSubject(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.EscapedPath() {
+		case "/api/v4/user":
+			w.Write([]byte(`{"id": 1, "username": "root"}`))
+		case "/api/v4/users":
+			w.Write([]byte(`[{"id": 42, "username": "alice"}]`))
+		default:
+			// Uzivatel neni clenem, Before zustane prazdne
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "404 Not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := gitlab.NewClient("token", gitlab.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sink := &memorySink{}
+	audit.Default().AddSink(sink)
+	defer audit.Default().Close()
+
+	level := gitlab.DeveloperPermissions
+	RemoveGroupMember(client, "platform/backend", "alice")
+	EditGroupMember(client, "platform/backend", "alice", level, nil)
+	RemoveProjectMember(client, "platform/legacy", "alice")
+	EditProjectMember(client, "platform/legacy", "alice", level, nil)
+
+	if len(sink.events) != 4 {
+		t.Fatalf("got %d events, want 4", len(sink.events))
+	}
+	for _, event := range sink.events {
+		if event.Subject == nil || event.Subject.ID != 42 || event.Subject.Username != "alice" {
+			t.Errorf("%s: subject %+v, want alice with ID 42", event.Action, event.Subject)
+		}
+		if event.Result != audit.ResultFailure {
+			t.Errorf("%s: result %s, want %s", event.Action, event.Result, audit.ResultFailure)
+		}
+	}
+}
+
+func TestEditMemberSendsAccessLevel(t *testing.T) {
+	bodies := map[string]map[string]any{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.EscapedPath() == "/api/v4/users":
+			w.Write([]byte(`[{"id": 42, "username": "bob"}]`))
+		case r.Method == http.MethodPut:
+			body := map[string]any{}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Error(err)
+			}
+			bodies[r.URL.EscapedPath()] = body
+			w.Write([]byte(`{"id": 42, "username": "bob", "access_level": 30}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "404 Not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := gitlab.NewClient("token", gitlab.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Zmena jen expirace posila aktualni access level
+	if err := EditGroupMember(client, "platform/backend", "bob", gitlab.DeveloperPermissions, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := EditProjectMember(client, "platform/legacy", "bob", gitlab.DeveloperPermissions, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"/api/v4/groups/platform%2Fbackend/members/42", "/api/v4/projects/platform%2Flegacy/members/42"} {
+		body, ok := bodies[path]
+		if !ok {
+			t.Errorf("no update request to %s", path)
+			continue
+		}
+		if body["access_level"] != float64(gitlab.DeveloperPermissions) {
+			t.Errorf("%s: access_level %v, want %d", path, body["access_level"], gitlab.DeveloperPermissions)
+		}
+		if body["expires_at"] != "" {
+			t.Errorf("%s: expires_at %v, want empty to remove the expiration", path, body["expires_at"])
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package gitlab
+
+import (
+	"fmt"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// GetNamespace returns the namespace (group or user) by its ID or full path
+func GetNamespace(client *gitlab.Client, idOrPath string) (*gitlab.Namespace, error) {
+	namespace, _, err := client.Namespaces.GetNamespace(idOrPath)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving namespace '%s': %w", idOrPath, err)
+	}
+	return namespace, nil
+}
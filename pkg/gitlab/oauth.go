@@ -0,0 +1,251 @@
+package gitlab
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"golang.org/x/oauth2"
+)
+
+// StoredToken is an OAuth2 token saved by login and refreshed on use
+type StoredToken struct {
+	URL      string        `json:"url"`
+	ClientID string        `json:"clientId,omitempty"`
+	Token    *oauth2.Token `json:"token"`
+}
+
+// ErrNotLoggedIn is returned when OAuth2 authentication has no stored token
+var ErrNotLoggedIn = errors.New("no stored OAuth2 token, run \"devops-cli gitlab-ce login\" first")
+
+// OAuthConfig returns OAuth2 endpoints of the GitLab instance
+func OAuthConfig(gitlabURL, clientID string, scopes []string) (*oauth2.Config, error) {
+	u, err := url.Parse(gitlabURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid GitLab URL %q", gitlabURL)
+	}
+	root := strings.TrimSuffix(strings.TrimSuffix(u.String(), "/"), "/api/v4")
+
+	return &oauth2.Config{
+		ClientID: clientID,
+		Scopes:   scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:       root + "/oauth/authorize",
+			TokenURL:      root + "/oauth/token",
+			DeviceAuthURL: root + "/oauth/authorize_device",
+		},
+	}, nil
+}
+
+// LoadToken reads a token saved by SaveToken, ErrNotLoggedIn when the file does not exist
+func LoadToken(path string) (*StoredToken, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotLoggedIn
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading token file: %w", err)
+	}
+
+	var stored StoredToken
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("invalid token file %s: %w", path, err)
+	}
+	if stored.Token == nil {
+		return nil, ErrNotLoggedIn
+	}
+	return &stored, nil
+}
+
+// SaveToken writes the token atomically, readable only by the owner
+func SaveToken(path string, stored *StoredToken) error {
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("error creating token directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("error writing token file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("error writing token file: %w", err)
+	}
+	return nil
+}
+
+// newOAuthClient uses the given access token or the stored one, stored tokens
+// are refreshed when they expire and the new token is saved back
+func newOAuthClient(options ClientOptions, httpClient *http.Client, clientOptions []gitlab.ClientOptionFunc) (*gitlab.Client, error) {
+	if options.Token != "" {
+		return gitlab.NewOAuthClient(options.Token, clientOptions...)
+	}
+	if options.TokenFile == "" {
+		return nil, ErrNotLoggedIn
+	}
+
+	stored, err := LoadToken(options.TokenFile)
+	if err != nil {
+		return nil, err
+	}
+	clientID := stored.ClientID
+	if options.OAuthClientID != "" {
+		clientID = options.OAuthClientID
+	}
+	config, err := OAuthConfig(options.URL, clientID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Obnova tokenu pouziva stejne TLS a proxy jako API
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{
+		Transport: httpClient.Transport,
+		Timeout:   httpClient.Timeout,
+	})
+	source := &storingTokenSource{
+		source: config.TokenSource(ctx, stored.Token),
+		path:   options.TokenFile,
+		stored: *stored,
+	}
+	// Hlavicku Authorization nastavuje oauth2.Transport vzdy s platnym tokenem
+	httpClient.Transport = &oauth2.Transport{Source: source, Base: httpClient.Transport}
+
+	return gitlab.NewOAuthClient(stored.Token.AccessToken, clientOptions...)
+}
+
+// storingTokenSource saves every refreshed token, GitLab refresh tokens are single use
+type storingTokenSource struct {
+	mu     sync.Mutex
+	source oauth2.TokenSource
+	path   string
+	stored StoredToken
+}
+
+func (s *storingTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, err := s.source.Token()
+	if err != nil {
+		return nil, fmt.Errorf("error refreshing OAuth2 token: %w", err)
+	}
+	if token.AccessToken != s.stored.Token.AccessToken {
+		s.stored.Token = token
+		if err := SaveToken(s.path, &s.stored); err != nil {
+			slog.Warn("failed to save refreshed OAuth2 token", "file", s.path, "error", err)
+		}
+	}
+	return token, nil
+}
+
+// DeviceLogin runs the OAuth2 device authorization grant,
+// prompt shows the verification URL and code to the user
+func DeviceLogin(ctx context.Context, config *oauth2.Config, httpClient *http.Client, prompt func(*oauth2.DeviceAuthResponse)) (*oauth2.Token, error) {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+
+	response, err := config.DeviceAuth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("device authorization failed: %w", err)
+	}
+	prompt(response)
+
+	token, err := config.DeviceAccessToken(ctx, response)
+	if err != nil {
+		return nil, fmt.Errorf("device authorization failed: %w", err)
+	}
+	return token, nil
+}
+
+// BrowserLogin runs the authorization code grant with PKCE. config.RedirectURL
+// must be a local http address registered in the GitLab application, open
+// shows the authorization URL to the user.
+func BrowserLogin(ctx context.Context, config *oauth2.Config, httpClient *http.Client, open func(authURL string)) (*oauth2.Token, error) {
+	redirect, err := url.Parse(config.RedirectURL)
+	if err != nil || redirect.Scheme != "http" {
+		return nil, fmt.Errorf("redirect URL must be a local http:// address, got %q", config.RedirectURL)
+	}
+
+	listener, err := net.Listen("tcp", redirect.Host)
+	if err != nil {
+		return nil, fmt.Errorf("cannot listen on %s: %w", redirect.Host, err)
+	}
+
+	state := randomString()
+	verifier := oauth2.GenerateVerifier()
+
+	type callback struct {
+		code string
+		err  error
+	}
+	done := make(chan callback, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(redirect.Path, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case query.Get("state") != state:
+			http.Error(w, "invalid state", http.StatusBadRequest)
+			return
+		case query.Get("error") != "":
+			done <- callback{err: fmt.Errorf("authorization denied: %s %s", query.Get("error"), query.Get("error_description"))}
+		default:
+			done <- callback{code: query.Get("code")}
+		}
+		fmt.Fprintln(w, "Authorization finished, you can close this window.")
+	})
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	defer server.Close()
+
+	open(config.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier)))
+
+	var result callback
+	select {
+	case result = <-done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	token, err := config.Exchange(context.WithValue(ctx, oauth2.HTTPClient, httpClient), result.code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("error exchanging authorization code: %w", err)
+	}
+	return token, nil
+}
+
+// PasswordLogin exchanges username and password for tokens (resource owner
+// password credentials grant), intended for the initial bootstrap
+func PasswordLogin(ctx context.Context, config *oauth2.Config, httpClient *http.Client, username, password string) (*oauth2.Token, error) {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+
+	token, err := config.PasswordCredentialsToken(ctx, username, password)
+	if err != nil {
+		return nil, fmt.Errorf("password authentication failed: %w", err)
+	}
+	return token, nil
+}
+
+func randomString() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
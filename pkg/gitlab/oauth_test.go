@@ -0,0 +1,166 @@
+package gitlab
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestLoadToken(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{name: "missing file", path: filepath.Join(dir, "missing.json"), wantErr: ErrNotLoggedIn.Error()},
+		{name: "without token", path: write("empty.json", `{"url": "https://gitlab.example.com"}`), wantErr: ErrNotLoggedIn.Error()},
+		{name: "invalid json", path: write("invalid.json", `{"token":`), wantErr: "invalid token file"},
+		{name: "token", path: write("token.json", `{"url": "https://gitlab.example.com", "clientId": "app", "token": {"access_token": "access"}}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored, err := LoadToken(tt.path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.ClientID != "app" || stored.Token.AccessToken != "access" {
+				t.Errorf("got %+v", stored)
+			}
+		})
+	}
+}
+
+func TestSaveToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "devops-cli", "token.json")
+	stored := &StoredToken{URL: "https://gitlab.example.com", Token: &oauth2.Token{AccessToken: "access", RefreshToken: "refresh"}}
+
+	if err := SaveToken(path, stored); err != nil {
+		t.Fatal(err)
+	}
+	assertMode(t, path, 0o600)
+	assertMode(t, filepath.Dir(path), 0o700|os.ModeDir)
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary file left behind: %v", err)
+	}
+
+	loaded, err := LoadToken(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.URL != stored.URL || loaded.Token.RefreshToken != "refresh" {
+		t.Errorf("got %+v", loaded)
+	}
+}
+
+func assertMode(t *testing.T, path string, want os.FileMode) {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode() != want {
+		t.Errorf("%s: mode %v, want %v", path, info.Mode(), want)
+	}
+}
+
+func TestOAuthClientRefresh(t *testing.T) {
+	var refreshed int
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oauth/token":
+			if err := r.ParseForm(); err != nil {
+				t.Error(err)
+			}
+			if r.Form.Get("grant_type") != "refresh_token" || r.Form.Get("refresh_token") != "old-refresh" || r.Form.Get("client_id") != "app" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error": "invalid_grant"}`))
+				return
+			}
+			refreshed++
+			w.Write([]byte(`{"access_token": "new-access", "refresh_token": "new-refresh", "token_type": "Bearer", "expires_in": 7200}`))
+		case "/api/v4/user":
+			authorization = r.Header.Get("Authorization")
+			w.Write([]byte(`{"id": 1, "username": "alice"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "404 Not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "token.json")
+	expired := &StoredToken{URL: server.URL, ClientID: "app", Token: &oauth2.Token{
+		AccessToken:  "old-access",
+		RefreshToken: "old-refresh",
+		TokenType:    "Bearer",
+		Expiry:       time.Now().Add(-time.Hour),
+	}}
+	if err := SaveToken(path, expired); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := NewClient(ClientOptions{URL: server.URL, AuthMethod: AuthOAuth2, TokenFile: path, Retries: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Dva pozadavky, token se obnovi jen jednou
+	for range 2 {
+		if _, _, err := client.Users.CurrentUser(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if refreshed != 1 {
+		t.Errorf("token refreshed %d times, want 1", refreshed)
+	}
+	if authorization != "Bearer new-access" {
+		t.Errorf("Authorization %q, want the refreshed token", authorization)
+	}
+
+	// Refresh token GitLabu plati jen jednou, novy musi byt ulozeny
+	assertMode(t, path, 0o600)
+	stored, err := LoadToken(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Token.AccessToken != "new-access" || stored.Token.RefreshToken != "new-refresh" || stored.ClientID != "app" {
+		t.Errorf("stored %+v with token %+v", stored, stored.Token)
+	}
+}
+
+func TestOAuthClientNotLoggedIn(t *testing.T) {
+	options := ClientOptions{URL: "https://gitlab.example.com", AuthMethod: AuthOAuth2}
+	if _, err := NewClient(options); !errors.Is(err, ErrNotLoggedIn) {
+		t.Errorf("without token file: error %v, want %v", err, ErrNotLoggedIn)
+	}
+
+	options.TokenFile = filepath.Join(t.TempDir(), "token.json")
+	if _, err := NewClient(options); !errors.Is(err, ErrNotLoggedIn) {
+		t.Errorf("missing token file: error %v, want %v", err, ErrNotLoggedIn)
+	}
+}
@@ -0,0 +1,60 @@
+package gitlab
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// transliterations of letters which NFD does not decompose to a base letter and diacritics
+var transliterations = map[rune]string{
+	'ł': "l", 'đ': "d", 'ð': "d", 'ø': "o", 'ħ': "h", 'ŧ': "t", 'ı': "i",
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'þ': "th",
+}
+
+// Slugify converts a display name to a group or project path. Diacritics are
+// removed, letters are lowercased and every run of other characters than
+// letters, digits, '_' and '.' becomes a single '-', e.g. "Správa sítí" -> "sprava-siti".
+func Slugify(name string) (string, error) {
+	var b strings.Builder
+	dash := false
+	write := func(s string) {
+		if dash && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		dash = false
+		b.WriteString(s)
+	}
+	for _, r := range norm.NFD.String(name) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Diakritika po rozkladu NFD
+			continue
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.'):
+			write(string(unicode.ToLower(r)))
+		case transliterations[unicode.ToLower(r)] != "":
+			// Napr. ł nebo ß se NFD nerozlozi, nahradime je ASCII obdobou
+			write(transliterations[unicode.ToLower(r)])
+		default:
+			dash = true
+		}
+	}
+
+	// GitLab nepovoli cestu zacinajici nebo koncici specialnim znakem, po odebrani
+	// .git/.atom muze na konci zustat dalsi, orezavame proto do ustaleni
+	path := b.String()
+	for {
+		trimmed := strings.Trim(path, "-_.")
+		trimmed = strings.TrimSuffix(strings.TrimSuffix(trimmed, ".git"), ".atom")
+		if trimmed == path {
+			break
+		}
+		path = trimmed
+	}
+	if path == "" {
+		return "", fmt.Errorf("cannot derive path from name %q, set the path explicitly", name)
+	}
+	return path, nil
+}
@@ -0,0 +1,41 @@
+package gitlab
+
+import "testing"
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"Backend":              "backend",
+		"Správa sítí":          "sprava-siti",
+		"Účetnictví & Finance": "ucetnictvi-finance",
+		"  Platform   Team  ":  "platform-team",
+		"api_v2.service":       "api_v2.service",
+		"Łódź":                 "lodz",
+		"Straße":               "strasse",
+		"STRAẞE":               "strasse",
+		"Đorđe Ørsted":         "dorde-orsted",
+		"Æsir Œuvre":           "aesir-oeuvre",
+		"Þór":                  "thor",
+		"İstanbul ılık":        "istanbul-ilik",
+		"München 2026":         "munchen-2026",
+		".hidden.":             "hidden",
+		"repo.git":             "repo",
+		"feed.atom":            "feed",
+		"Data—Warehouse (DWH)": "data-warehouse-dwh",
+	}
+	for name, want := range tests {
+		got, err := Slugify(name)
+		if err != nil {
+			t.Errorf("Slugify(%q): %v", name, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("Slugify(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	for _, name := range []string{"", "   ", "???", "東京", "._.", "- . _"} {
+		if got, err := Slugify(name); err == nil {
+			t.Errorf("Slugify(%q) = %q, want error", name, got)
+		}
+	}
+}
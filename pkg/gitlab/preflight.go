@@ -0,0 +1,251 @@
+package gitlab
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// Requirement lists permissions a command needs before it changes anything
+type Requirement struct {
+	// Scopes of the access token
+	Scopes []string
+	// Admin is needed e.g. to create users
+	Admin bool
+	// CreateGroups is needed to create top-level groups
+	CreateGroups bool
+	// OwnerOf are groups (ID or full path) whose members will be changed
+	OwnerOf []string
+	// MaintainerOf are projects (ID or full path) whose settings, members
+	// or shares will be changed
+	MaintainerOf []string
+	// ProjectOwnerOf are projects (ID or full path) which will be deleted
+	// or transferred
+	ProjectOwnerOf []string
+	// CreateProjectIn are namespaces (ID or full path) of new projects,
+	// empty string is the namespace of the current user
+	CreateProjectIn []string
+	// CreateSubgroupIn are groups (ID or full path) of new subgroups
+	CreateSubgroupIn []string
+}
+
+// CheckResult is the outcome of one pre-flight check
+type CheckResult struct {
+	Check  string `json:"check"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// PreflightReport collects results of all pre-flight checks
+type PreflightReport struct {
+	User   string        `json:"user"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// Failures returns the failed checks
+func (r *PreflightReport) Failures() []CheckResult {
+	var failed []CheckResult
+	for _, check := range r.Checks {
+		if !check.OK {
+			failed = append(failed, check)
+		}
+	}
+	return failed
+}
+
+func (r *PreflightReport) add(check string, ok bool, detail string, args ...any) {
+	r.Checks = append(r.Checks, CheckResult{Check: check, OK: ok, Detail: fmt.Sprintf(detail, args...)})
+}
+
+// Preflight verifies that the current token and user satisfy the requirement.
+// It only reads from GitLab, an error is returned when the checks cannot be run at all.
+func Preflight(client *gitlab.Client, requirement Requirement) (*PreflightReport, error) {
+	identity, err := GetIdentity(client)
+	if err != nil {
+		return nil, err
+	}
+	report := &PreflightReport{User: identity.Username}
+
+	if len(requirement.Scopes) > 0 {
+		scopes := "token scopes " + strings.Join(requirement.Scopes, ", ")
+		switch {
+		case identity.Token == nil:
+			// OAuth2 a job token nemaji self endpoint, scopes overi az samotne API
+			report.add(scopes, true, "not verified, token details are not available")
+		case len(identity.Token.MissingScopes(requirement.Scopes)) > 0:
+			report.add(scopes, false, "token %q lacks %s", identity.Token.Name, strings.Join(identity.Token.MissingScopes(requirement.Scopes), ", "))
+		default:
+			report.add(scopes, true, "token %q", identity.Token.Name)
+		}
+	}
+	if identity.Token != nil && !identity.Token.Active {
+		report.add("active token", false, "token %q is revoked or expired", identity.Token.Name)
+	}
+
+	if requirement.Admin {
+		report.add("administrator", identity.IsAdmin, "user %s is admin: %t", identity.Username, identity.IsAdmin)
+	}
+
+	if requirement.CreateGroups {
+		ok := identity.IsAdmin || identity.CanCreateGroup
+		report.add("create top-level groups", ok, "user %s can create groups: %t", identity.Username, ok)
+	}
+
+	for _, group := range requirement.OwnerOf {
+		check := "owner of group " + group
+		if identity.IsAdmin {
+			report.add(check, true, "user %s is admin", identity.Username)
+			continue
+		}
+		level, err := groupAccessLevel(client, group, identity.UserID)
+		if err != nil {
+			return nil, err
+		}
+		report.add(check, level >= gitlab.OwnerPermissions, "user %s has %s access", identity.Username, AccessLevelName(level))
+	}
+
+	for _, project := range requirement.MaintainerOf {
+		if err := checkProjectAccess(client, report, identity, project, gitlab.MaintainerPermissions); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, project := range requirement.ProjectOwnerOf {
+		if err := checkProjectAccess(client, report, identity, project, gitlab.OwnerPermissions); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, namespace := range requirement.CreateProjectIn {
+		if err := checkProjectCreation(client, report, identity, namespace); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, parent := range requirement.CreateSubgroupIn {
+		if err := checkSubgroupCreation(client, report, identity, parent); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+func checkProjectCreation(client *gitlab.Client, report *PreflightReport, identity *Identity, namespace string) error {
+	// Bez namespace se projekt zaklada v osobnim namespace uzivatele
+	if namespace == "" || namespace == "0" || namespace == identity.Username {
+		report.add("create project in personal namespace", identity.CanCreateProject, "user %s can create projects: %t", identity.Username, identity.CanCreateProject)
+		return nil
+	}
+
+	check := "create project in " + namespace
+	ns, err := GetNamespace(client, namespace)
+	if err != nil {
+		if IsNotFound(err) {
+			report.add(check, false, "namespace does not exist or is not visible to %s", identity.Username)
+			return nil
+		}
+		return err
+	}
+	if ns.Kind == "user" {
+		ok := identity.IsAdmin || ns.Path == identity.Username
+		report.add(check, ok, "personal namespace of %s", ns.Path)
+		return nil
+	}
+	if identity.IsAdmin {
+		report.add(check, true, "user %s is admin", identity.Username)
+		return nil
+	}
+
+	group, _, err := client.Groups.GetGroup(ns.ID, nil)
+	if err != nil {
+		return fmt.Errorf("error retrieving group '%s': %w", ns.FullPath, err)
+	}
+	level, err := groupAccessLevel(client, strconv.Itoa(ns.ID), identity.UserID)
+	if err != nil {
+		return err
+	}
+
+	// Minimalni role pro zakladani projektu urcuje nastaveni skupiny
+	needed := gitlab.MaintainerPermissions
+	switch group.ProjectCreationLevel {
+	case gitlab.DeveloperProjectCreation:
+		needed = gitlab.DeveloperPermissions
+	case gitlab.OwnerProjectCreation:
+		needed = gitlab.OwnerPermissions
+	case gitlab.NoOneProjectCreation:
+		needed = gitlab.AdminPermissions
+	}
+	report.add(check, level >= needed, "user %s has %s access, project creation requires %s", identity.Username, AccessLevelName(level), AccessLevelName(needed))
+	return nil
+}
+
+func checkProjectAccess(client *gitlab.Client, report *PreflightReport, identity *Identity, project string, needed gitlab.AccessLevelValue) error {
+	check := AccessLevelName(needed) + " of project " + project
+	if identity.IsAdmin {
+		report.add(check, true, "user %s is admin", identity.Username)
+		return nil
+	}
+	level, err := projectAccessLevel(client, project, identity.UserID)
+	if err != nil {
+		return err
+	}
+	report.add(check, level >= needed, "user %s has %s access", identity.Username, AccessLevelName(level))
+	return nil
+}
+
+// groupAccessLevel returns the access level of the user in the group including
+// inherited membership, NoPermissions when the user is not a member
+func groupAccessLevel(client *gitlab.Client, group string, userID int) (gitlab.AccessLevelValue, error) {
+	member, _, err := client.GroupMembers.GetInheritedGroupMember(group, userID)
+	if err != nil {
+		if IsNotFound(err) {
+			return gitlab.NoPermissions, nil
+		}
+		return 0, fmt.Errorf("error retrieving membership in group '%s': %w", group, err)
+	}
+	return member.AccessLevel, nil
+}
+
+// projectAccessLevel returns the access level of the user in the project
+// including membership inherited from groups, NoPermissions when the user is not a member
+func projectAccessLevel(client *gitlab.Client, project string, userID int) (gitlab.AccessLevelValue, error) {
+	member, _, err := client.ProjectMembers.GetInheritedProjectMember(project, userID)
+	if err != nil {
+		if IsNotFound(err) {
+			return gitlab.NoPermissions, nil
+		}
+		return 0, fmt.Errorf("error retrieving membership in project '%s': %w", project, err)
+	}
+	return member.AccessLevel, nil
+}
+
+func checkSubgroupCreation(client *gitlab.Client, report *PreflightReport, identity *Identity, parent string) error {
+	check := "create subgroup in " + parent
+	group, _, err := client.Groups.GetGroup(parent, nil)
+	if err != nil {
+		if IsNotFound(err) {
+			report.add(check, false, "group does not exist or is not visible to %s", identity.Username)
+			return nil
+		}
+		return fmt.Errorf("error retrieving group '%s': %w", parent, err)
+	}
+	if identity.IsAdmin {
+		report.add(check, true, "user %s is admin", identity.Username)
+		return nil
+	}
+
+	level, err := groupAccessLevel(client, strconv.Itoa(group.ID), identity.UserID)
+	if err != nil {
+		return err
+	}
+
+	needed := gitlab.OwnerPermissions
+	if group.SubGroupCreationLevel == gitlab.MaintainerSubGroupCreationLevelValue {
+		needed = gitlab.MaintainerPermissions
+	}
+	report.add(check, level >= needed, "user %s has %s access, subgroup creation requires %s", identity.Username, AccessLevelName(level), AccessLevelName(needed))
+	return nil
+}
@@ -0,0 +1,110 @@
+package gitlab
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// newFakeGitLab serves fixed JSON responses by escaped API path, other paths return 404
+func newFakeGitLab(t *testing.T, responses map[string]string) *gitlab.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		body, ok := responses[r.URL.EscapedPath()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			body = `{"message": "404 Not found"}`
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := gitlab.NewClient("token", gitlab.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestPreflight(t *testing.T) {
+	client := newFakeGitLab(t, map[string]string{
+		"/api/v4/user":                                     `{"id": 5, "username": "alice", "can_create_group": false, "can_create_project": true}`,
+		"/api/v4/personal_access_tokens/self":              `{"id": 1, "name": "ci", "scopes": ["read_api"], "active": true}`,
+		"/api/v4/groups/platform/members/all/5":            `{"id": 5, "username": "alice", "access_level": 40}`,
+		"/api/v4/projects/platform%2Flegacy/members/all/5": `{"id": 5, "username": "alice", "access_level": 40}`,
+	})
+
+	report, err := Preflight(client, Requirement{
+		Scopes:          []string{"api"},
+		CreateGroups:    true,
+		OwnerOf:         []string{"platform"},
+		MaintainerOf:    []string{"platform/legacy", "platform/billing"},
+		ProjectOwnerOf:  []string{"platform/legacy"},
+		CreateProjectIn: []string{""},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]bool{}
+	for _, check := range report.Checks {
+		got[check.Check] = check.OK
+	}
+	want := map[string]bool{
+		"token scopes api":                       false,
+		"create top-level groups":                false,
+		"owner of group platform":                false,
+		"maintainer of project platform/legacy":  true,
+		"maintainer of project platform/billing": false,
+		"owner of project platform/legacy":       false,
+		"create project in personal namespace":   true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if report.User != "alice" || len(report.Failures()) != 5 {
+		t.Errorf("user %s with %d failures, want alice with 5", report.User, len(report.Failures()))
+	}
+}
+
+func TestCheckProjectCreation(t *testing.T) {
+	tests := []struct {
+		creationLevel string
+		accessLevel   string
+		want          bool
+	}{
+		{creationLevel: "developer", accessLevel: "30", want: true},
+		{creationLevel: "developer", accessLevel: "20"},
+		{creationLevel: "maintainer", accessLevel: "30"},
+		{creationLevel: "maintainer", accessLevel: "40", want: true},
+		{creationLevel: "owner", accessLevel: "40"},
+		{creationLevel: "owner", accessLevel: "50", want: true},
+		{creationLevel: "noone", accessLevel: "50"},
+		// Bez nastaveni plati vychozi uroven maintainer
+		{creationLevel: "", accessLevel: "30"},
+		{creationLevel: "", accessLevel: "40", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.creationLevel+"/"+tt.accessLevel, func(t *testing.T) {
+			client := newFakeGitLab(t, map[string]string{
+				"/api/v4/namespaces/platform%2Fbackend": `{"id": 10, "kind": "group", "path": "backend", "full_path": "platform/backend"}`,
+				"/api/v4/groups/10":                     `{"id": 10, "full_path": "platform/backend", "project_creation_level": "` + tt.creationLevel + `"}`,
+				"/api/v4/groups/10/members/all/5":       `{"id": 5, "username": "alice", "access_level": ` + tt.accessLevel + `}`,
+			})
+
+			report := &PreflightReport{}
+			identity := &Identity{UserID: 5, Username: "alice"}
+			if err := checkProjectCreation(client, report, identity, "platform/backend"); err != nil {
+				t.Fatal(err)
+			}
+			if len(report.Checks) != 1 || report.Checks[0].OK != tt.want {
+				t.Errorf("checks %+v, want ok %t", report.Checks, tt.want)
+			}
+		})
+	}
+}
@@ -2,8 +2,12 @@ package gitlab
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/Cloud-for-You/devops-cli/pkg/audit"
 	gitlab "gitlab.com/gitlab-org/api/client-go"
 )
 
@@ -37,20 +41,270 @@ func ListProjects(client *gitlab.Client) ([]*gitlab.Project, error) {
 	return allProjects, nil
 }
 
-func CreateProject(client *gitlab.Client, projectName string, namespaceID int, projectDescription string, visibility string, maintainerGroupName *string, developerGroupName *string) (*gitlab.Project, *gitlab.Response, error) {
+// CreateProjectWithShares creates the project with all options supported by the API
+// and shares it with the groups. All groups are checked before the project is
+// created, so a typo does not leave a project without its groups behind.
+func CreateProjectWithShares(client *gitlab.Client, projectOptions *gitlab.CreateProjectOptions, shares []GroupShare) (*gitlab.Project, *gitlab.Response, error) {
+	if err := checkShareGroups(client, shares); err != nil {
+		return nil, nil, err
+	}
+
+	project, res, err := CreateProjectWithOptions(client, projectOptions)
+	if err != nil {
+		return nil, res, err
+	}
 
-  projectOptions := &gitlab.CreateProjectOptions{
-	  Name:        gitlab.Ptr(projectName),
-		Path:        gitlab.Ptr(projectName),
-		Description: gitlab.Ptr(projectDescription),
-		NamespaceID: gitlab.Ptr(namespaceID),
-		Visibility:  gitlab.Ptr(gitlab.VisibilityValue(visibility)),
+	for _, share := range shares {
+		if err := ShareProject(client, project.PathWithNamespace, share.Group, share.AccessLevel, nil); err != nil {
+			return project, res, fmt.Errorf("project %s was created, but %w", project.PathWithNamespace, err)
+		}
+		slog.Info("project shared", "project", project.PathWithNamespace, "group", share.Group, "accessLevel", AccessLevelName(share.AccessLevel))
 	}
 
+	return project, res, nil
+}
+
+// CreateProjectWithOptions creates a project with all options supported by the API
+func CreateProjectWithOptions(client *gitlab.Client, projectOptions *gitlab.CreateProjectOptions) (*gitlab.Project, *gitlab.Response, error) {
 	project, res, err := client.Projects.CreateProject(projectOptions)
+
+	event := audit.Event{
+		Action: "project.create",
+		Target: audit.Target{Type: "project"},
+	}
+	if projectOptions.Name != nil {
+		event.Target.Name = *projectOptions.Name
+	}
+	if project != nil {
+		event.Target.ID = project.ID
+		event.Target.Name = project.PathWithNamespace
+		event.After = projectState(project)
+	}
+	recordAudit(client, event, err)
+
 	if err != nil {
-		log.Fatalf("Failed to create GitLab repository: %v", err)
+		return nil, res, err
 	}
 
 	return project, res, nil
-}
\ No newline at end of file
+}
+
+// GetProject returns the project given by ID or full path
+func GetProject(client *gitlab.Client, projectID string) (*gitlab.Project, error) {
+	project, _, err := client.Projects.GetProject(projectID, nil)
+	if err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// UpdateProject changes settings of the project given by ID or full path
+func UpdateProject(client *gitlab.Client, projectID string, options *gitlab.EditProjectOptions) (*gitlab.Project, error) {
+	var before *objectState
+	if audit.Enabled() {
+		if current, err := GetProject(client, projectID); err == nil {
+			before = projectState(current)
+		}
+	}
+
+	project, _, err := client.Projects.EditProject(projectID, options)
+
+	event := audit.Event{
+		Action: "project.update",
+		Target: audit.Target{Type: "project", Name: projectID},
+		Before: before,
+	}
+	if project != nil {
+		event.Target.ID = project.ID
+		event.Target.Name = project.PathWithNamespace
+		event.After = projectState(project)
+	}
+	recordAudit(client, event, err)
+
+	if err != nil {
+		return nil, fmt.Errorf("error updating project '%s': %w", projectID, err)
+	}
+
+	slog.Debug("project updated", "project", project.PathWithNamespace)
+	return project, nil
+}
+
+// ListProjectMembers returns direct members of the project given by ID or full path
+func ListProjectMembers(client *gitlab.Client, projectID string) ([]*gitlab.ProjectMember, error) {
+	var allMembers []*gitlab.ProjectMember
+	page := 1
+	perPage := 20
+
+	for {
+		options := &gitlab.ListProjectMembersOptions{
+			ListOptions: gitlab.ListOptions{
+				Page:    page,
+				PerPage: perPage,
+			},
+		}
+
+		members, res, err := client.ProjectMembers.ListProjectMembers(projectID, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve projectMembers: %v", err)
+		}
+
+		allMembers = append(allMembers, members...)
+
+		if res.CurrentPage >= res.TotalPages {
+			break
+		}
+
+		page++
+	}
+
+	return allMembers, nil
+}
+
+// ListGroupProjects returns projects of the group given by ID or full path including
+// projects of all its subgroups. Projects of other namespaces only shared into the
+// group are not returned, archived filters archived (true) or active (false) projects,
+// nil returns both.
+func ListGroupProjects(client *gitlab.Client, groupID string, archived *bool) ([]*gitlab.Project, error) {
+	var allProjects []*gitlab.Project
+	options := &gitlab.ListGroupProjectsOptions{
+		ListOptions:      gitlab.ListOptions{Page: 1, PerPage: 100},
+		IncludeSubGroups: gitlab.Ptr(true),
+		// API vraci i projekty jinych namespace sdilene do skupiny
+		WithShared: gitlab.Ptr(false),
+		Archived:   archived,
+	}
+
+	for {
+		projects, res, err := client.Groups.ListGroupProjects(groupID, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve projects of group '%s': %w", groupID, err)
+		}
+		allProjects = append(allProjects, projects...)
+
+		if res.CurrentPage >= res.TotalPages {
+			break
+		}
+		options.Page++
+	}
+
+	return allProjects, nil
+}
+
+// ArchiveProject makes the project given by ID or full path read-only
+func ArchiveProject(client *gitlab.Client, projectID string) (*gitlab.Project, error) {
+	project, _, err := client.Projects.ArchiveProject(projectID)
+	auditProject(client, "project.archive", projectID, nil, project, err)
+	if err != nil {
+		return nil, fmt.Errorf("error archiving project '%s': %w", projectID, err)
+	}
+	return project, nil
+}
+
+// UnarchiveProject makes an archived project writable again
+func UnarchiveProject(client *gitlab.Client, projectID string) (*gitlab.Project, error) {
+	project, _, err := client.Projects.UnarchiveProject(projectID)
+	auditProject(client, "project.unarchive", projectID, nil, project, err)
+	if err != nil {
+		return nil, fmt.Errorf("error unarchiving project '%s': %w", projectID, err)
+	}
+	return project, nil
+}
+
+// DeleteProject deletes the project given by ID or full path. With delayed deletion
+// enabled the project is only marked for deletion and the date of the marking is
+// returned, permanently removes a project which is already marked.
+func DeleteProject(client *gitlab.Client, projectID string, permanently bool) (*time.Time, error) {
+	project, err := GetProject(client, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving project '%s': %w", projectID, err)
+	}
+
+	options := &gitlab.DeleteProjectOptions{}
+	if permanently {
+		options.PermanentlyRemove = gitlab.Ptr(true)
+		options.FullPath = gitlab.Ptr(project.PathWithNamespace)
+	}
+	_, err = client.Projects.DeleteProject(project.ID, options)
+	auditProject(client, "project.delete", projectID, project, nil, err)
+	if err != nil {
+		return nil, fmt.Errorf("error deleting project '%s': %w", project.PathWithNamespace, err)
+	}
+
+	// Projekt oznaceny ke smazani je dal dostupny
+	if deleted, err := GetProject(client, strconv.Itoa(project.ID)); err == nil && deleted.MarkedForDeletionAt != nil {
+		return (*time.Time)(deleted.MarkedForDeletionAt), nil
+	}
+	return nil, nil
+}
+
+// RestoreProject restores a project marked for deletion
+func RestoreProject(client *gitlab.Client, projectID string) (*gitlab.Project, error) {
+	// Klient endpoint pro obnoveni projektu nema
+	var project *gitlab.Project
+	req, err := client.NewRequest(http.MethodPost, "projects/"+gitlab.PathEscape(projectID)+"/restore", nil, nil)
+	if err == nil {
+		_, err = client.Do(req, &project)
+	}
+	auditProject(client, "project.restore", projectID, nil, project, err)
+	if err != nil {
+		return nil, fmt.Errorf("error restoring project '%s': %w", projectID, err)
+	}
+	return project, nil
+}
+
+// TransferProject moves the project into the namespace given by ID or full path
+func TransferProject(client *gitlab.Client, projectID string, namespace string) (*gitlab.Project, error) {
+	project, err := GetProject(client, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving project '%s': %w", projectID, err)
+	}
+
+	transferred, _, err := client.Projects.TransferProject(project.ID, &gitlab.TransferProjectOptions{Namespace: namespace})
+	auditProject(client, "project.transfer", projectID, project, transferred, err)
+	if err != nil {
+		return nil, fmt.Errorf("error transferring project '%s': %w", project.PathWithNamespace, err)
+	}
+	return transferred, nil
+}
+
+// ForkProject forks the project given by ID or full path, without namespace in
+// options into the namespace of the current user
+func ForkProject(client *gitlab.Client, projectID string, options *gitlab.ForkProjectOptions) (*gitlab.Project, error) {
+	fork, _, err := client.Projects.ForkProject(projectID, options)
+
+	event := audit.Event{
+		Action: "project.fork",
+		Target: audit.Target{Type: "project", Name: projectID},
+	}
+	if fork != nil {
+		event.After = projectState(fork)
+	}
+	recordAudit(client, event, err)
+
+	if err != nil {
+		return nil, fmt.Errorf("error forking project '%s': %w", projectID, err)
+	}
+	return fork, nil
+}
+
+// auditProject records a change of the project, the target is taken from the
+// state before the change, or after it when the state before is not known
+func auditProject(client *gitlab.Client, action string, projectID string, before, after *gitlab.Project, err error) {
+	event := audit.Event{
+		Action: action,
+		Target: audit.Target{Type: "project", Name: projectID},
+	}
+	if before != nil {
+		event.Target.ID = before.ID
+		event.Target.Name = before.PathWithNamespace
+		event.Before = projectState(before)
+	}
+	if after != nil {
+		if before == nil {
+			event.Target.ID = after.ID
+			event.Target.Name = after.PathWithNamespace
+		}
+		event.After = projectState(after)
+	}
+	recordAudit(client, event, err)
+}
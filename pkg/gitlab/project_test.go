@@ -0,0 +1,69 @@
+package gitlab
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+func TestListGroupProjectsQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		archived *bool
+		want     url.Values
+	}{
+		{
+			name: "all projects",
+			want: url.Values{"include_subgroups": {"true"}, "with_shared": {"false"}, "page": {"1"}, "per_page": {"100"}},
+		},
+		{
+			name:     "active projects",
+			archived: gitlab.Ptr(false),
+			want:     url.Values{"include_subgroups": {"true"}, "with_shared": {"false"}, "archived": {"false"}, "page": {"1"}, "per_page": {"100"}},
+		},
+		{
+			name:     "archived projects",
+			archived: gitlab.Ptr(true),
+			want:     url.Values{"include_subgroups": {"true"}, "with_shared": {"false"}, "archived": {"true"}, "page": {"1"}, "per_page": {"100"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var query url.Values
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.EscapedPath() != "/api/v4/groups/platform%2Fbackend/projects" {
+					t.Errorf("unexpected path %s", r.URL.EscapedPath())
+				}
+				query = r.URL.Query()
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`[{"id": 1, "path_with_namespace": "platform/backend/api"}]`))
+			}))
+			defer server.Close()
+
+			client, err := gitlab.NewClient("token", gitlab.WithBaseURL(server.URL))
+			if err != nil {
+				t.Fatal(err)
+			}
+			projects, err := ListGroupProjects(client, "platform/backend", tt.archived)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(projects) != 1 {
+				t.Fatalf("got %d projects, want 1", len(projects))
+			}
+
+			if len(query) != len(tt.want) {
+				t.Errorf("query %v, want %v", query, tt.want)
+			}
+			for key, want := range tt.want {
+				if got := query.Get(key); got != want[0] {
+					t.Errorf("%s = %q, want %q", key, got, want[0])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package gitlab
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/Cloud-for-You/devops-cli/pkg/audit"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// ScaffoldActions reads all files of the directory as actions of a commit creating
+// them. The .git directory is skipped, symbolic links are not supported by the
+// Commits API and are skipped with a warning.
+func ScaffoldActions(dir string) ([]*gitlab.CommitActionOptions, error) {
+	var actions []*gitlab.CommitActionOptions
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			slog.Warn("scaffold file skipped, only regular files are supported", "file", path)
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		// Base64 zachova i binarni soubory
+		actions = append(actions, &gitlab.CommitActionOptions{
+			Action:          gitlab.Ptr(gitlab.FileCreate),
+			FilePath:        gitlab.Ptr(filepath.ToSlash(rel)),
+			Content:         gitlab.Ptr(base64.StdEncoding.EncodeToString(content)),
+			Encoding:        gitlab.Ptr("base64"),
+			ExecuteFilemode: gitlab.Ptr(info.Mode()&0o111 != 0),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scaffold directory: %w", err)
+	}
+	if len(actions) == 0 {
+		return nil, fmt.Errorf("scaffold directory %s contains no files", dir)
+	}
+	return actions, nil
+}
+
+// CommitScaffold creates the files in one commit on the branch of the project given
+// by ID or full path, the branch is created in an empty repository
+func CommitScaffold(client *gitlab.Client, projectID string, branch string, message string, actions []*gitlab.CommitActionOptions) (*gitlab.Commit, error) {
+	commit, _, err := client.Commits.CreateCommit(projectID, &gitlab.CreateCommitOptions{
+		Branch:        gitlab.Ptr(branch),
+		CommitMessage: gitlab.Ptr(message),
+		Actions:       actions,
+	})
+
+	event := audit.Event{
+		Action: "project.commit",
+		Target: audit.Target{Type: "project", Name: projectID},
+	}
+	if commit != nil {
+		event.After = commitState{Branch: branch, ID: commit.ID, Files: len(actions)}
+	}
+	recordAudit(client, event, err)
+
+	if err != nil {
+		return nil, fmt.Errorf("error committing scaffold to branch %s: %w", branch, err)
+	}
+
+	slog.Debug("scaffold committed", "project", projectID, "branch", branch, "commit", commit.ShortID, "files", len(actions))
+	return commit, nil
+}
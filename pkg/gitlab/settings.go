@@ -0,0 +1,176 @@
+package gitlab
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// ProjectSettings are project settings managed by the CLI, nil fields are left unchanged
+type ProjectSettings struct {
+	DefaultBranch *string `mapstructure:"defaultBranch" yaml:"defaultBranch,omitempty" json:"defaultBranch,omitempty"`
+	// MergeMethod is merge, rebase_merge or ff
+	MergeMethod *string `mapstructure:"mergeMethod" yaml:"mergeMethod,omitempty" json:"mergeMethod,omitempty"`
+	// SquashOption is never, always, default_on or default_off
+	SquashOption                              *string `mapstructure:"squashOption" yaml:"squashOption,omitempty" json:"squashOption,omitempty"`
+	OnlyAllowMergeIfPipelineSucceeds          *bool   `mapstructure:"onlyAllowMergeIfPipelineSucceeds" yaml:"onlyAllowMergeIfPipelineSucceeds,omitempty" json:"onlyAllowMergeIfPipelineSucceeds,omitempty"`
+	OnlyAllowMergeIfAllDiscussionsAreResolved *bool   `mapstructure:"onlyAllowMergeIfAllDiscussionsAreResolved" yaml:"onlyAllowMergeIfAllDiscussionsAreResolved,omitempty" json:"onlyAllowMergeIfAllDiscussionsAreResolved,omitempty"`
+	RemoveSourceBranchAfterMerge              *bool   `mapstructure:"removeSourceBranchAfterMerge" yaml:"removeSourceBranchAfterMerge,omitempty" json:"removeSourceBranchAfterMerge,omitempty"`
+	// CIConfigPath is the path of the CI configuration, empty string is .gitlab-ci.yml
+	CIConfigPath *string `mapstructure:"ciConfigPath" yaml:"ciConfigPath,omitempty" json:"ciConfigPath,omitempty"`
+
+	// Zapnuti funkci projektu, API je nastavuje pres *_access_level
+	IssuesEnabled            *bool `mapstructure:"issuesEnabled" yaml:"issuesEnabled,omitempty" json:"issuesEnabled,omitempty"`
+	WikiEnabled              *bool `mapstructure:"wikiEnabled" yaml:"wikiEnabled,omitempty" json:"wikiEnabled,omitempty"`
+	SnippetsEnabled          *bool `mapstructure:"snippetsEnabled" yaml:"snippetsEnabled,omitempty" json:"snippetsEnabled,omitempty"`
+	ContainerRegistryEnabled *bool `mapstructure:"containerRegistryEnabled" yaml:"containerRegistryEnabled,omitempty" json:"containerRegistryEnabled,omitempty"`
+	PackagesEnabled          *bool `mapstructure:"packagesEnabled" yaml:"packagesEnabled,omitempty" json:"packagesEnabled,omitempty"`
+}
+
+var (
+	mergeMethods  = []string{"merge", "rebase_merge", "ff"}
+	squashOptions = []string{"never", "always", "default_on", "default_off"}
+)
+
+// Validate checks values of enumerated settings
+func (s ProjectSettings) Validate() error {
+	var errs []error
+	if s.MergeMethod != nil && !slices.Contains(mergeMethods, *s.MergeMethod) {
+		errs = append(errs, fmt.Errorf("invalid mergeMethod %q (supported: %v)", *s.MergeMethod, mergeMethods))
+	}
+	if s.SquashOption != nil && !slices.Contains(squashOptions, *s.SquashOption) {
+		errs = append(errs, fmt.Errorf("invalid squashOption %q (supported: %v)", *s.SquashOption, squashOptions))
+	}
+	return errors.Join(errs...)
+}
+
+// Changes describes settings of the project which differ from the desired ones
+// as "name: current -> desired"
+func (s ProjectSettings) Changes(project *gitlab.Project) []string {
+	var changes []string
+	settingChange(&changes, "defaultBranch", s.DefaultBranch, project.DefaultBranch)
+	settingChange(&changes, "mergeMethod", s.MergeMethod, string(project.MergeMethod))
+	settingChange(&changes, "squashOption", s.SquashOption, string(project.SquashOption))
+	settingChange(&changes, "onlyAllowMergeIfPipelineSucceeds", s.OnlyAllowMergeIfPipelineSucceeds, project.OnlyAllowMergeIfPipelineSucceeds)
+	settingChange(&changes, "onlyAllowMergeIfAllDiscussionsAreResolved", s.OnlyAllowMergeIfAllDiscussionsAreResolved, project.OnlyAllowMergeIfAllDiscussionsAreResolved)
+	settingChange(&changes, "removeSourceBranchAfterMerge", s.RemoveSourceBranchAfterMerge, project.RemoveSourceBranchAfterMerge)
+	settingChange(&changes, "ciConfigPath", s.CIConfigPath, project.CIConfigPath)
+	settingChange(&changes, "issuesEnabled", s.IssuesEnabled, featureEnabled(project.IssuesAccessLevel, project.IssuesEnabled))
+	settingChange(&changes, "wikiEnabled", s.WikiEnabled, featureEnabled(project.WikiAccessLevel, project.WikiEnabled))
+	settingChange(&changes, "snippetsEnabled", s.SnippetsEnabled, featureEnabled(project.SnippetsAccessLevel, project.SnippetsEnabled))
+	settingChange(&changes, "containerRegistryEnabled", s.ContainerRegistryEnabled, featureEnabled(project.ContainerRegistryAccessLevel, project.ContainerRegistryEnabled))
+	settingChange(&changes, "packagesEnabled", s.PackagesEnabled, project.PackagesEnabled)
+	return changes
+}
+
+// Describe lists the settings which are set as "name: value"
+func (s ProjectSettings) Describe() []string {
+	var settings []string
+	settingValue(&settings, "defaultBranch", s.DefaultBranch)
+	settingValue(&settings, "mergeMethod", s.MergeMethod)
+	settingValue(&settings, "squashOption", s.SquashOption)
+	settingValue(&settings, "onlyAllowMergeIfPipelineSucceeds", s.OnlyAllowMergeIfPipelineSucceeds)
+	settingValue(&settings, "onlyAllowMergeIfAllDiscussionsAreResolved", s.OnlyAllowMergeIfAllDiscussionsAreResolved)
+	settingValue(&settings, "removeSourceBranchAfterMerge", s.RemoveSourceBranchAfterMerge)
+	settingValue(&settings, "ciConfigPath", s.CIConfigPath)
+	settingValue(&settings, "issuesEnabled", s.IssuesEnabled)
+	settingValue(&settings, "wikiEnabled", s.WikiEnabled)
+	settingValue(&settings, "snippetsEnabled", s.SnippetsEnabled)
+	settingValue(&settings, "containerRegistryEnabled", s.ContainerRegistryEnabled)
+	settingValue(&settings, "packagesEnabled", s.PackagesEnabled)
+	return settings
+}
+
+// CreateOptions copies the settings into options of a new project
+func (s ProjectSettings) CreateOptions(options *gitlab.CreateProjectOptions) {
+	options.DefaultBranch = s.DefaultBranch
+	options.MergeMethod = (*gitlab.MergeMethodValue)(s.MergeMethod)
+	options.SquashOption = (*gitlab.SquashOptionValue)(s.SquashOption)
+	options.OnlyAllowMergeIfPipelineSucceeds = s.OnlyAllowMergeIfPipelineSucceeds
+	options.OnlyAllowMergeIfAllDiscussionsAreResolved = s.OnlyAllowMergeIfAllDiscussionsAreResolved
+	options.RemoveSourceBranchAfterMerge = s.RemoveSourceBranchAfterMerge
+	options.CIConfigPath = s.CIConfigPath
+	options.IssuesAccessLevel = featureAccess(s.IssuesEnabled)
+	options.WikiAccessLevel = featureAccess(s.WikiEnabled)
+	options.SnippetsAccessLevel = featureAccess(s.SnippetsEnabled)
+	options.ContainerRegistryAccessLevel = featureAccess(s.ContainerRegistryEnabled)
+	options.PackagesEnabled = s.PackagesEnabled
+}
+
+// EditOptions copies the settings into options of a project update
+func (s ProjectSettings) EditOptions(options *gitlab.EditProjectOptions) {
+	options.DefaultBranch = s.DefaultBranch
+	options.MergeMethod = (*gitlab.MergeMethodValue)(s.MergeMethod)
+	options.SquashOption = (*gitlab.SquashOptionValue)(s.SquashOption)
+	options.OnlyAllowMergeIfPipelineSucceeds = s.OnlyAllowMergeIfPipelineSucceeds
+	options.OnlyAllowMergeIfAllDiscussionsAreResolved = s.OnlyAllowMergeIfAllDiscussionsAreResolved
+	options.RemoveSourceBranchAfterMerge = s.RemoveSourceBranchAfterMerge
+	options.CIConfigPath = s.CIConfigPath
+	options.IssuesAccessLevel = featureAccess(s.IssuesEnabled)
+	options.WikiAccessLevel = featureAccess(s.WikiEnabled)
+	options.SnippetsAccessLevel = featureAccess(s.SnippetsEnabled)
+	options.ContainerRegistryAccessLevel = featureAccess(s.ContainerRegistryEnabled)
+	options.PackagesEnabled = s.PackagesEnabled
+}
+
+// Merge returns the settings overridden by fields set in other
+func (s ProjectSettings) Merge(other ProjectSettings) ProjectSettings {
+	merge(&s.DefaultBranch, other.DefaultBranch)
+	merge(&s.MergeMethod, other.MergeMethod)
+	merge(&s.SquashOption, other.SquashOption)
+	merge(&s.OnlyAllowMergeIfPipelineSucceeds, other.OnlyAllowMergeIfPipelineSucceeds)
+	merge(&s.OnlyAllowMergeIfAllDiscussionsAreResolved, other.OnlyAllowMergeIfAllDiscussionsAreResolved)
+	merge(&s.RemoveSourceBranchAfterMerge, other.RemoveSourceBranchAfterMerge)
+	merge(&s.CIConfigPath, other.CIConfigPath)
+	merge(&s.IssuesEnabled, other.IssuesEnabled)
+	merge(&s.WikiEnabled, other.WikiEnabled)
+	merge(&s.SnippetsEnabled, other.SnippetsEnabled)
+	merge(&s.ContainerRegistryEnabled, other.ContainerRegistryEnabled)
+	merge(&s.PackagesEnabled, other.PackagesEnabled)
+	return s
+}
+
+// Empty reports whether no setting is set
+func (s ProjectSettings) Empty() bool {
+	return len(s.Describe()) == 0
+}
+
+// featureEnabled reports whether a project feature is enabled, older instances
+// do not return the access level
+func featureEnabled(level gitlab.AccessControlValue, legacy bool) bool {
+	if level == "" {
+		return legacy
+	}
+	return level != gitlab.DisabledAccessControl
+}
+
+// featureAccess converts a feature toggle to its access level, nil keeps the current one
+func featureAccess(enabled *bool) *gitlab.AccessControlValue {
+	if enabled == nil {
+		return nil
+	}
+	if *enabled {
+		return gitlab.Ptr(gitlab.EnabledAccessControl)
+	}
+	return gitlab.Ptr(gitlab.DisabledAccessControl)
+}
+
+func merge[T any](value **T, override *T) {
+	if override != nil {
+		*value = override
+	}
+}
+
+func settingChange[T comparable](changes *[]string, name string, desired *T, current T) {
+	if desired != nil && *desired != current {
+		*changes = append(*changes, fmt.Sprintf("%s: %v -> %v", name, current, *desired))
+	}
+}
+
+func settingValue[T any](settings *[]string, name string, value *T) {
+	if value != nil {
+		*settings = append(*settings, fmt.Sprintf("%s: %v", name, *value))
+	}
+}
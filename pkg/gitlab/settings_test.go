@@ -0,0 +1,119 @@
+package gitlab
+
+import (
+	"reflect"
+	"testing"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+func TestProjectSettingsMerge(t *testing.T) {
+	profile := ProjectSettings{
+		MergeMethod:                      gitlab.Ptr("ff"),
+		SquashOption:                     gitlab.Ptr("default_on"),
+		OnlyAllowMergeIfPipelineSucceeds: gitlab.Ptr(true),
+		WikiEnabled:                      gitlab.Ptr(false),
+	}
+
+	tests := []struct {
+		name  string
+		flags ProjectSettings
+		want  ProjectSettings
+	}{
+		{
+			name:  "no flags keep the profile",
+			flags: ProjectSettings{},
+			want:  profile,
+		},
+		{
+			name: "flags override the profile",
+			flags: ProjectSettings{
+				SquashOption:                     gitlab.Ptr("always"),
+				OnlyAllowMergeIfPipelineSucceeds: gitlab.Ptr(false),
+				CIConfigPath:                     gitlab.Ptr(""),
+			},
+			want: ProjectSettings{
+				MergeMethod:                      gitlab.Ptr("ff"),
+				SquashOption:                     gitlab.Ptr("always"),
+				OnlyAllowMergeIfPipelineSucceeds: gitlab.Ptr(false),
+				CIConfigPath:                     gitlab.Ptr(""),
+				WikiEnabled:                      gitlab.Ptr(false),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := profile.Merge(tt.flags)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got.Describe(), tt.want.Describe())
+			}
+		})
+	}
+
+	// Merge nemeni profil
+	if *profile.SquashOption != "default_on" || *profile.OnlyAllowMergeIfPipelineSucceeds != true {
+		t.Errorf("profile changed: %v", profile.Describe())
+	}
+}
+
+func TestProjectSettingsChanges(t *testing.T) {
+	project := &gitlab.Project{
+		DefaultBranch:                    "main",
+		MergeMethod:                      gitlab.FastForwardMerge,
+		SquashOption:                     gitlab.SquashOptionDefaultOn,
+		OnlyAllowMergeIfPipelineSucceeds: true,
+		CIConfigPath:                     "",
+		WikiAccessLevel:                  gitlab.DisabledAccessControl,
+		WikiEnabled:                      true,
+		// Starsi instance nevraci access level, plati puvodni priznak
+		SnippetsEnabled: true,
+	}
+
+	tests := []struct {
+		name     string
+		settings ProjectSettings
+		want     []string
+	}{
+		{
+			name: "nothing changes",
+			settings: ProjectSettings{
+				DefaultBranch:                    gitlab.Ptr("main"),
+				MergeMethod:                      gitlab.Ptr("ff"),
+				SquashOption:                     gitlab.Ptr("default_on"),
+				OnlyAllowMergeIfPipelineSucceeds: gitlab.Ptr(true),
+				CIConfigPath:                     gitlab.Ptr(""),
+				WikiEnabled:                      gitlab.Ptr(false),
+				SnippetsEnabled:                  gitlab.Ptr(true),
+			},
+		},
+		{
+			name:     "empty settings",
+			settings: ProjectSettings{},
+		},
+		{
+			name: "changed settings",
+			settings: ProjectSettings{
+				MergeMethod:     gitlab.Ptr("merge"),
+				CIConfigPath:    gitlab.Ptr("ci/pipeline.yml"),
+				WikiEnabled:     gitlab.Ptr(true),
+				SnippetsEnabled: gitlab.Ptr(false),
+			},
+			want: []string{
+				"mergeMethod: ff -> merge",
+				"ciConfigPath:  -> ci/pipeline.yml",
+				"wikiEnabled: false -> true",
+				"snippetsEnabled: true -> false",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.settings.Changes(project)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package gitlab
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Cloud-for-You/devops-cli/pkg/audit"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// GroupShare is a group an object is shared with and the maximum access level of its members
+type GroupShare struct {
+	Group       string
+	AccessLevel gitlab.AccessLevelValue
+}
+
+// ParseGroupShare parses "group=level", e.g. "platform/backend=developer"
+func ParseGroupShare(value string) (GroupShare, error) {
+	group, level, ok := strings.Cut(value, "=")
+	if !ok || strings.TrimSpace(group) == "" {
+		return GroupShare{}, fmt.Errorf("invalid share %q, use group=level (e.g. platform/backend=developer)", value)
+	}
+	accessLevel, err := ParseAccessLevel(level)
+	if err != nil {
+		return GroupShare{}, fmt.Errorf("share %q: %w", value, err)
+	}
+	return GroupShare{Group: strings.TrimSpace(group), AccessLevel: accessLevel}, nil
+}
+
+// checkShareGroups returns an error naming all groups of shares which do not exist
+func checkShareGroups(client *gitlab.Client, shares []GroupShare) error {
+	var missing []string
+	for _, share := range shares {
+		if _, err := GetGroup(client, share.Group); err != nil {
+			if !IsNotFound(err) {
+				return fmt.Errorf("error retrieving group '%s': %w", share.Group, err)
+			}
+			missing = append(missing, share.Group)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("groups to share with do not exist or are not visible: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// ShareGroup shares the group with another group (both given by ID or full path),
+// members of sharedWith get at most accessLevel in the group
+func ShareGroup(client *gitlab.Client, groupID string, sharedWith string, accessLevel gitlab.AccessLevelValue, expiresAt *time.Time) error {
+	target, err := GetGroup(client, sharedWith)
+	if err != nil {
+		return fmt.Errorf("error retrieving group '%s': %w", sharedWith, err)
+	}
+
+	options := &gitlab.ShareGroupWithGroupOptions{
+		GroupID:     &target.ID,
+		GroupAccess: &accessLevel,
+	}
+	if expiresAt != nil {
+		options.ExpiresAt = gitlab.Ptr(gitlab.ISOTime(*expiresAt))
+	}
+	_, _, err = client.Groups.ShareGroupWithGroup(groupID, options)
+
+	recordAudit(client, audit.Event{
+		Action: "group.share",
+		Target: audit.Target{Type: "group", Name: groupID},
+		After:  shareState{Group: target.FullPath, AccessLevel: accessLevel, ExpiresAt: options.ExpiresAt},
+	}, err)
+
+	if err != nil {
+		return fmt.Errorf("error sharing group with '%s': %w", target.FullPath, err)
+	}
+
+	slog.Debug("group shared", "group", groupID, "sharedWith", target.FullPath)
+	return nil
+}
+
+// UnshareGroup removes the link of the group shared with another group
+func UnshareGroup(client *gitlab.Client, groupID string, sharedWith string) error {
+	target, err := GetGroup(client, sharedWith)
+	if err != nil {
+		return fmt.Errorf("error retrieving group '%s': %w", sharedWith, err)
+	}
+
+	_, err = client.Groups.UnshareGroupFromGroup(groupID, target.ID)
+
+	recordAudit(client, audit.Event{
+		Action: "group.unshare",
+		Target: audit.Target{Type: "group", Name: groupID},
+		Before: shareState{Group: target.FullPath},
+	}, err)
+
+	if err != nil {
+		return fmt.Errorf("error removing share with '%s': %w", target.FullPath, err)
+	}
+
+	slog.Debug("group unshared", "group", groupID, "sharedWith", target.FullPath)
+	return nil
+}
+
+// ShareProject shares the project given by ID or full path with a group,
+// members of the group get at most accessLevel in the project
+func ShareProject(client *gitlab.Client, projectID string, sharedWith string, accessLevel gitlab.AccessLevelValue, expiresAt *time.Time) error {
+	target, err := GetGroup(client, sharedWith)
+	if err != nil {
+		return fmt.Errorf("error retrieving group '%s': %w", sharedWith, err)
+	}
+
+	_, err = client.Projects.ShareProjectWithGroup(projectID, &gitlab.ShareWithGroupOptions{
+		GroupID:     &target.ID,
+		GroupAccess: &accessLevel,
+		ExpiresAt:   expiryDate(expiresAt),
+	})
+
+	after := shareState{Group: target.FullPath, AccessLevel: accessLevel}
+	if expiresAt != nil {
+		after.ExpiresAt = gitlab.Ptr(gitlab.ISOTime(*expiresAt))
+	}
+	recordAudit(client, audit.Event{
+		Action: "project.share",
+		Target: audit.Target{Type: "project", Name: projectID},
+		After:  after,
+	}, err)
+
+	if err != nil {
+		return fmt.Errorf("error sharing project with '%s': %w", target.FullPath, err)
+	}
+
+	slog.Debug("project shared", "project", projectID, "sharedWith", target.FullPath)
+	return nil
+}
+
+// UnshareProject removes the link of the project shared with a group
+func UnshareProject(client *gitlab.Client, projectID string, sharedWith string) error {
+	target, err := GetGroup(client, sharedWith)
+	if err != nil {
+		return fmt.Errorf("error retrieving group '%s': %w", sharedWith, err)
+	}
+
+	_, err = client.Projects.DeleteSharedProjectFromGroup(projectID, target.ID)
+
+	recordAudit(client, audit.Event{
+		Action: "project.unshare",
+		Target: audit.Target{Type: "project", Name: projectID},
+		Before: shareState{Group: target.FullPath},
+	}, err)
+
+	if err != nil {
+		return fmt.Errorf("error removing share with '%s': %w", target.FullPath, err)
+	}
+
+	slog.Debug("project unshared", "project", projectID, "sharedWith", target.FullPath)
+	return nil
+}
@@ -0,0 +1,65 @@
+package logging
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Podporovane formaty vystupu
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+var base = slog.Default()
+
+// Setup configures the process wide logger. Every record of the default
+// logger carries run_id, a correlation ID of this process run.
+func Setup(level, format string, w io.Writer) error {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	options := &slog.HandlerOptions{Level: lvl}
+
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case FormatText, "":
+		handler = slog.NewTextHandler(w, options)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, options)
+	default:
+		return fmt.Errorf("unsupported log format %q (supported: %s, %s)", format, FormatText, FormatJSON)
+	}
+
+	base = slog.New(handler)
+	slog.SetDefault(base.With("run_id", NewRunID()))
+	return nil
+}
+
+// ForRun returns a logger with a new correlation ID. Long running processes
+// use it to tell apart records of single runs (e.g. scheduled synchronizations).
+func ForRun(args ...any) *slog.Logger {
+	return base.With(append([]any{"run_id", NewRunID()}, args...)...)
+}
+
+// ParseLevel converts debug, info, warn or error to slog.Level
+func ParseLevel(level string) (slog.Level, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return 0, fmt.Errorf("unsupported log level %q (supported: debug, info, warn, error)", level)
+	}
+	return lvl, nil
+}
+
+// NewRunID returns a random 16 character correlation ID
+func NewRunID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
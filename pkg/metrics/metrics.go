@@ -0,0 +1,169 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry holds all devops-cli metrics. Metrics are always collected, they
+// are exposed only when requested (Handler in daemon mode, WriteTextfile after
+// one-shot runs).
+var Registry = prometheus.NewRegistry()
+
+var (
+	syncRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "devops_cli_groupsync_runs_total",
+		Help: "Number of groupsync runs by source, sync name and status.",
+	}, []string{"source", "sync", "status"})
+
+	syncRunDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "devops_cli_groupsync_run_duration_seconds",
+		Help:    "Duration of groupsync runs.",
+		Buckets: []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200, 3600},
+	}, []string{"source", "sync"})
+
+	syncLastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "devops_cli_groupsync_last_success_timestamp_seconds",
+		Help: "Unix time of the last successful groupsync run.",
+	}, []string{"source", "sync"})
+
+	syncMembers = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "devops_cli_groupsync_members_total",
+		Help: "Member changes applied by groupsync per GitLab group, action (add, remove, update-access, update-expiry) and result.",
+	}, []string{"group", "action", "result"})
+
+	ldapQueries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "devops_cli_ldap_queries_total",
+		Help: "Number of LDAP operations by operation and result.",
+	}, []string{"operation", "result"})
+
+	ldapQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "devops_cli_ldap_query_duration_seconds",
+		Help:    "Latency of LDAP operations.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"operation"})
+
+	gitlabRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "devops_cli_gitlab_api_requests_total",
+		Help: "Number of GitLab API requests by method, endpoint and status code.",
+	}, []string{"method", "endpoint", "code"})
+
+	gitlabRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "devops_cli_gitlab_api_request_duration_seconds",
+		Help:    "Latency of GitLab API requests.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "endpoint"})
+)
+
+func init() {
+	Registry.MustRegister(
+		syncRuns, syncRunDuration, syncLastSuccess, syncMembers,
+		ldapQueries, ldapQueryDuration,
+		gitlabRequests, gitlabRequestDuration,
+	)
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// WriteTextfile writes the metrics for the node_exporter textfile collector.
+// The file is written atomically, path should end with .prom.
+func WriteTextfile(path string) error {
+	return prometheus.WriteToTextfile(path, Registry)
+}
+
+// ObserveSyncRun records one finished groupsync run
+func ObserveSyncRun(source, sync string, duration time.Duration, success bool) {
+	status := "success"
+	if !success {
+		status = "failed"
+	}
+	syncRuns.WithLabelValues(source, sync, status).Inc()
+	syncRunDuration.WithLabelValues(source, sync).Observe(duration.Seconds())
+	if success {
+		syncLastSuccess.WithLabelValues(source, sync).SetToCurrentTime()
+	}
+}
+
+// ObserveMemberChange records one applied (or failed) member change
+func ObserveMemberChange(group, action string, err error) {
+	syncMembers.WithLabelValues(group, action, result(err)).Inc()
+}
+
+// ObserveLDAP records one LDAP operation started at start
+func ObserveLDAP(operation string, start time.Time, err error) {
+	ldapQueries.WithLabelValues(operation, result(err)).Inc()
+	ldapQueryDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
+
+func result(err error) string {
+	if err != nil {
+		return "failed"
+	}
+	return "success"
+}
+
+// InstrumentTransport counts and times every request sent through next.
+// Use it as the transport of the HTTP client passed to the GitLab client.
+func InstrumentTransport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		start := time.Now()
+		// Dekodovana cesta by rozdelila "platform%2Fbackend" na vice segmentu
+		endpoint := Endpoint(req.URL.EscapedPath())
+
+		res, err := next.RoundTrip(req)
+
+		code := "error"
+		if err == nil {
+			code = strconv.Itoa(res.StatusCode)
+		}
+		gitlabRequests.WithLabelValues(req.Method, endpoint, code).Inc()
+		gitlabRequestDuration.WithLabelValues(req.Method, endpoint).Observe(time.Since(start).Seconds())
+
+		return res, err
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Kolekce, za kterymi v ceste nasleduje identifikator objektu
+var idCollections = map[string]bool{
+	"groups": true, "projects": true, "users": true, "namespaces": true,
+	"members": true, "personal_access_tokens": true, "share": true,
+}
+
+// Segmenty za kolekci, ktere nejsou identifikatorem objektu
+var keywords = map[string]bool{"self": true, "all": true}
+
+// Endpoint turns an escaped API path into a low-cardinality label,
+// e.g. /api/v4/groups/my%2Fgroup/members/42 becomes /groups/:id/members/:id
+func Endpoint(path string) string {
+	path = strings.TrimPrefix(path, "/api/v4")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i, segment := range segments {
+		if segment == "" {
+			continue
+		}
+		_, numErr := strconv.Atoi(segment)
+		if numErr == nil || strings.Contains(segment, "%") || (i > 0 && idCollections[segments[i-1]] && !keywords[segment]) {
+			segments[i] = ":id"
+		}
+	}
+
+	return "/" + strings.Join(segments, "/")
+}
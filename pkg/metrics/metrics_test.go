@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+func TestEndpoint(t *testing.T) {
+	tests := map[string]string{
+		"/api/v4/groups/platform%2Fbackend/members":    "/groups/:id/members",
+		"/api/v4/groups/platform%2Fbackend/members/42": "/groups/:id/members/:id",
+		"/api/v4/groups/101/members/all":               "/groups/:id/members/all",
+		"/api/v4/projects/platform%2Flegacy/share/7":   "/projects/:id/share/:id",
+		"/api/v4/projects/12/repository/commits":       "/projects/:id/repository/commits",
+		"/api/v4/personal_access_tokens/self":          "/personal_access_tokens/self",
+		"/api/v4/users":                                "/users",
+		"/api/v4/namespaces/alice":                     "/namespaces/:id",
+		"/api/v4/version":                              "/version",
+	}
+	for path, want := range tests {
+		if got := Endpoint(path); got != want {
+			t.Errorf("Endpoint(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestInstrumentTransportUsesEscapedPath(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client, err := gitlab.NewClient("token",
+		gitlab.WithBaseURL(server.URL),
+		gitlab.WithHTTPClient(&http.Client{Transport: InstrumentTransport(nil)}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := client.Groups.ListGroupMembers("platform/backend/api", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	families, err := Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var endpoints []string
+	for _, family := range families {
+		if family.GetName() != "devops_cli_gitlab_api_requests_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "endpoint" {
+					endpoints = append(endpoints, label.GetValue())
+				}
+			}
+		}
+	}
+	if len(endpoints) != 1 || endpoints[0] != "/groups/:id/members" {
+		t.Errorf("endpoints %v, want [/groups/:id/members]", endpoints)
+	}
+}
@@ -0,0 +1,127 @@
+package secret
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// FlagAnnotation marks flags whose value is a secret. Such flags accept
+// references (env:, file:, vault:) and are never printed in clear text.
+const FlagAnnotation = "devops-cli/secret"
+
+const masked = "********"
+
+// Resolve returns the secret referenced by ref. Supported references are
+//
+//	env:VAR          value of the environment variable VAR
+//	file:/path       content of the file, trailing newline is trimmed
+//	vault:path#key   key of the HashiCorp Vault KV secret at path
+//
+// Any other value is returned unchanged, so literal secrets keep working.
+func Resolve(ref string) (string, error) {
+	scheme, rest, found := strings.Cut(ref, ":")
+	if !found {
+		return ref, nil
+	}
+
+	switch scheme {
+	case "env":
+		value, ok := os.LookupEnv(rest)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", rest)
+		}
+		return value, nil
+	case "file":
+		data, err := os.ReadFile(rest)
+		if err != nil {
+			return "", fmt.Errorf("error reading secret file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	case "vault":
+		path, key, ok := strings.Cut(rest, "#")
+		if !ok || path == "" || key == "" {
+			return "", fmt.Errorf("invalid vault reference %q, expected vault:path#key", ref)
+		}
+		client, err := NewVaultClientFromEnv()
+		if err != nil {
+			return "", err
+		}
+		return client.Read(path, key)
+	default:
+		return ref, nil
+	}
+}
+
+// IsReference reports whether the value points to a secret instead of containing it
+func IsReference(value string) bool {
+	for _, prefix := range []string{"env:", "file:", "vault:"} {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Mask hides a secret value for display. References are shown as they are,
+// they do not contain the secret itself.
+func Mask(value string) string {
+	if value == "" || IsReference(value) {
+		return value
+	}
+	return masked
+}
+
+// MarkFlag annotates the flag as a secret
+func MarkFlag(flags *pflag.FlagSet, name string) error {
+	return flags.SetAnnotation(name, FlagAnnotation, []string{"true"})
+}
+
+// IsSecretFlag reports whether the flag was marked by MarkFlag
+func IsSecretFlag(flag *pflag.Flag) bool {
+	_, ok := flag.Annotations[FlagAnnotation]
+	return ok
+}
+
+// ResolveFlags replaces references in all secret flags by the secret values.
+// lookup provides values of flags not given on the command line (e.g. from
+// the configuration file), it may be nil.
+func ResolveFlags(flags *pflag.FlagSet, lookup func(name string) string) error {
+	var errs []error
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if !IsSecretFlag(flag) {
+			return
+		}
+
+		value := flag.Value.String()
+		if !flag.Changed && lookup != nil {
+			value = lookup(flag.Name)
+		}
+		if value == "" {
+			return
+		}
+
+		resolved, err := Resolve(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("--%s: %w", flag.Name, err))
+			return
+		}
+		// Value.Set nemeni Changed, hodnotu z konfigurace tak lze dal rozlisit od zadane na prikazove radce
+		if err := flag.Value.Set(resolved); err != nil {
+			errs = append(errs, fmt.Errorf("--%s: %w", flag.Name, err))
+		}
+	})
+
+	return errors.Join(errs...)
+}
+
+// FlagValue returns the flag value suitable for display, secrets are masked
+func FlagValue(flag *pflag.Flag) string {
+	if IsSecretFlag(flag) {
+		return Mask(flag.Value.String())
+	}
+	return flag.Value.String()
+}
@@ -0,0 +1,74 @@
+package secret
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	server := newFakeVault(t)
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", "root-token")
+	t.Setenv("VAULT_NAMESPACE", "")
+	t.Setenv("GITLAB_TOKEN", "env-token")
+
+	file := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(file, []byte("file-token\r\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr string
+	}{
+		{ref: "literal-token", want: "literal-token"},
+		{ref: "https://gitlab.example.com", want: "https://gitlab.example.com"},
+		{ref: "env:GITLAB_TOKEN", want: "env-token"},
+		{ref: "env:MISSING_TOKEN", wantErr: "MISSING_TOKEN is not set"},
+		{ref: "file:" + file, want: "file-token"},
+		{ref: "file:" + file + ".missing", wantErr: "error reading secret file"},
+		{ref: "vault:kv/gitlab#token", want: "v1-token"},
+		{ref: "vault:secret/gitlab#token", want: "v2-token"},
+		{ref: "vault:secret/gitlab", wantErr: "expected vault:path#key"},
+		{ref: "vault:#token", wantErr: "expected vault:path#key"},
+		{ref: "vault:secret/gitlab#", wantErr: "expected vault:path#key"},
+		{ref: "vault:secret/gitlab#password", wantErr: `key "password" not found`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := Resolve(tt.ref)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMask(t *testing.T) {
+	tests := map[string]string{
+		"":                    "",
+		"glpat-secret":        masked,
+		"env:GITLAB_TOKEN":    "env:GITLAB_TOKEN",
+		"file:/run/token":     "file:/run/token",
+		"vault:kv/gitlab#key": "vault:kv/gitlab#key",
+		"vaultpassword":       masked,
+	}
+	for value, want := range tests {
+		if got := Mask(value); got != want {
+			t.Errorf("Mask(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package secret
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// VaultClient reads secrets from the KV secrets engine (version 1 and 2)
+// of HashiCorp Vault using its HTTP API.
+type VaultClient struct {
+	Address    string
+	Token      string
+	Namespace  string
+	HTTPClient *http.Client
+}
+
+// NewVaultClientFromEnv configures the client the same way the vault CLI does:
+// VAULT_ADDR, VAULT_TOKEN (or ~/.vault-token), VAULT_NAMESPACE, VAULT_CACERT
+// and VAULT_SKIP_VERIFY.
+func NewVaultClientFromEnv() (*VaultClient, error) {
+	address := os.Getenv("VAULT_ADDR")
+	if address == "" {
+		return nil, fmt.Errorf("VAULT_ADDR must be set to read secrets from Vault")
+	}
+
+	token := os.Getenv("VAULT_TOKEN")
+	if token == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			if data, err := os.ReadFile(filepath.Join(home, ".vault-token")); err == nil {
+				token = strings.TrimSpace(string(data))
+			}
+		}
+	}
+	if token == "" {
+		return nil, fmt.Errorf("VAULT_TOKEN must be set to read secrets from Vault")
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: os.Getenv("VAULT_SKIP_VERIFY") == "true" || os.Getenv("VAULT_SKIP_VERIFY") == "1",
+	}
+	if caCert := os.Getenv("VAULT_CACERT"); caCert != "" {
+		pem, err := os.ReadFile(caCert)
+		if err != nil {
+			return nil, fmt.Errorf("error reading VAULT_CACERT: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", caCert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &VaultClient{
+		Address:   strings.TrimRight(address, "/"),
+		Token:     token,
+		Namespace: os.Getenv("VAULT_NAMESPACE"),
+		HTTPClient: &http.Client{
+			Transport: transport,
+			Timeout:   30 * time.Second,
+		},
+	}, nil
+}
+
+// Read returns one key of the secret stored at path. The path is given the
+// same way as to `vault kv get`, e.g. secret/gitlab for a KV v2 engine mounted
+// at secret/, the data/ segment is added automatically.
+func (c *VaultClient) Read(path, key string) (string, error) {
+	path = strings.Trim(path, "/")
+
+	mount, version, err := c.mountInfo(path)
+	if err != nil {
+		return "", err
+	}
+
+	apiPath := path
+	if version == "2" {
+		rel := strings.TrimPrefix(path, mount)
+		if !strings.HasPrefix(rel, "data/") {
+			apiPath = mount + "data/" + rel
+		}
+	}
+
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := c.get(apiPath, &secret); err != nil {
+		return "", fmt.Errorf("error reading vault secret %s: %w", path, err)
+	}
+
+	data := secret.Data
+	if version == "2" {
+		nested, ok := data["data"].(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("vault secret %s has no data", path)
+		}
+		data = nested
+	}
+
+	value, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("key %q not found in vault secret %s", key, path)
+	}
+	return fmt.Sprint(value), nil
+}
+
+// mountInfo returns the mount path (with trailing slash) and KV version of the engine holding path
+func (c *VaultClient) mountInfo(path string) (string, string, error) {
+	var mount struct {
+		Data struct {
+			Path    string            `json:"path"`
+			Options map[string]string `json:"options"`
+		} `json:"data"`
+	}
+	if err := c.get("sys/internal/ui/mounts/"+path, &mount); err != nil {
+		return "", "", fmt.Errorf("error reading vault mount of %s: %w", path, err)
+	}
+	return mount.Data.Path, mount.Data.Options["version"], nil
+}
+
+func (c *VaultClient) get(path string, out interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, c.Address+"/v1/"+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", c.Token)
+	req.Header.Set("X-Vault-Request", "true")
+	if c.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.Namespace)
+	}
+
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("vault returned %s: %s", res.Status, strings.TrimSpace(string(body)))
+	}
+
+	return json.NewDecoder(res.Body).Decode(out)
+}
@@ -0,0 +1,121 @@
+package secret
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newFakeVault serves a KV v1 engine mounted at kv/ and a KV v2 engine mounted at secret/
+func newFakeVault(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root-token" || r.Header.Get("X-Vault-Request") != "true" {
+			http.Error(w, `{"errors": ["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+		if r.Header.Get("X-Vault-Namespace") != "" && r.Header.Get("X-Vault-Namespace") != "team" {
+			http.Error(w, `{"errors": ["unknown namespace"]}`, http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		path := strings.TrimPrefix(r.URL.Path, "/v1/")
+		switch {
+		case strings.HasPrefix(path, "sys/internal/ui/mounts/kv/"):
+			w.Write([]byte(`{"data": {"path": "kv/", "type": "kv", "options": {"version": "1"}}}`))
+		case strings.HasPrefix(path, "sys/internal/ui/mounts/secret/"):
+			w.Write([]byte(`{"data": {"path": "secret/", "type": "kv", "options": {"version": "2"}}}`))
+		case path == "kv/gitlab":
+			w.Write([]byte(`{"data": {"token": "v1-token", "port": 8080}}`))
+		case path == "secret/data/gitlab":
+			w.Write([]byte(`{"data": {"data": {"token": "v2-token"}, "metadata": {"version": 3}}}`))
+		case path == "secret/data/deleted":
+			w.Write([]byte(`{"data": {"data": null, "metadata": {"deletion_time": "2026-01-01T00:00:00Z"}}}`))
+		default:
+			http.Error(w, `{"errors": []}`, http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestVaultRead(t *testing.T) {
+	server := newFakeVault(t)
+
+	tests := []struct {
+		name    string
+		path    string
+		key     string
+		want    string
+		wantErr string
+	}{
+		{name: "kv v1", path: "kv/gitlab", key: "token", want: "v1-token"},
+		{name: "kv v1 number", path: "kv/gitlab", key: "port", want: "8080"},
+		{name: "kv v2", path: "secret/gitlab", key: "token", want: "v2-token"},
+		{name: "kv v2 with data segment", path: "secret/data/gitlab", key: "token", want: "v2-token"},
+		{name: "slashes are trimmed", path: "/secret/gitlab/", key: "token", want: "v2-token"},
+		{name: "missing key", path: "secret/gitlab", key: "password", wantErr: `key "password" not found`},
+		{name: "deleted kv v2 secret", path: "secret/deleted", key: "token", wantErr: "has no data"},
+		{name: "missing secret", path: "kv/missing", key: "token", wantErr: "404 Not Found"},
+		{name: "unknown mount", path: "other/gitlab", key: "token", wantErr: "error reading vault mount"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &VaultClient{Address: server.URL, Token: "root-token", HTTPClient: server.Client()}
+			got, err := client.Read(tt.path, tt.key)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVaultReadHeaders(t *testing.T) {
+	server := newFakeVault(t)
+
+	client := &VaultClient{Address: server.URL, Token: "wrong", HTTPClient: server.Client()}
+	if _, err := client.Read("kv/gitlab", "token"); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("error %v, want 403", err)
+	}
+
+	client = &VaultClient{Address: server.URL, Token: "root-token", Namespace: "team", HTTPClient: server.Client()}
+	if _, err := client.Read("kv/gitlab", "token"); err != nil {
+		t.Errorf("read with namespace: %v", err)
+	}
+}
+
+func TestNewVaultClientFromEnv(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("VAULT_ADDR", "")
+	t.Setenv("VAULT_TOKEN", "")
+	if _, err := NewVaultClientFromEnv(); err == nil {
+		t.Error("client without VAULT_ADDR")
+	}
+
+	t.Setenv("VAULT_ADDR", "https://vault.example.com/")
+	if _, err := NewVaultClientFromEnv(); err == nil {
+		t.Error("client without VAULT_TOKEN")
+	}
+
+	t.Setenv("VAULT_TOKEN", "root-token")
+	t.Setenv("VAULT_NAMESPACE", "team")
+	client, err := NewVaultClientFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.Address != "https://vault.example.com" || client.Token != "root-token" || client.Namespace != "team" {
+		t.Errorf("got %+v", client)
+	}
+}